@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/NickyBoy89/uxnvm/uxn"
+)
+
+// newMixer plays the four Audio devices of the machine together
+func newMixer(machine *uxn.Machine) *uxn.Mixer {
+	var voices []*uxn.AudioDevice
+	for slot := audioSlots; slot < audioSlots+4; slot++ {
+		voices = append(voices, machine.Devices[slot].(*uxn.AudioDevice))
+	}
+	return uxn.NewMixer(voices...)
+}
+
+// A silentMixer plays the Audio devices without making a sound, for frontends
+// that have no audio output. Notes still advance and end once per frame, so
+// programs that wait for the Audio vector keep running
+type silentMixer struct {
+	mixer   *uxn.Mixer
+	samples []int16
+}
+
+// newSilentMixer creates a mixer that plays a single frame of audio at a time,
+// when there are `frameRate` frames per second
+func newSilentMixer(machine *uxn.Machine, frameRate int) *silentMixer {
+	return &silentMixer{
+		mixer:   newMixer(machine),
+		samples: make([]int16, 2*uxn.SampleRate/frameRate),
+	}
+}
+
+// frame plays the next frame of audio, and throws it away
+func (s *silentMixer) frame() {
+	s.mixer.Mix(s.samples)
+}
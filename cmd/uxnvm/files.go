@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NickyBoy89/uxnvm/uxn"
+)
+
+// openRoot opens the files that the File devices can access, which are either
+// a directory, or a read-only zip or tar archive
+func openRoot(path string) (fs.FS, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return uxn.DirFS(path), nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		// The archive is read from as files are opened, so it stays open
+		return uxn.ZipFS(file, info.Size())
+	case ".tar":
+		defer file.Close()
+		return uxn.TarFS(file)
+	default:
+		file.Close()
+		return nil, fmt.Errorf("%v is not a directory, or a zip or tar archive", path)
+	}
+}
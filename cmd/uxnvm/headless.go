@@ -0,0 +1,71 @@
+package main
+
+import (
+	"image/png"
+	"os"
+
+	"github.com/NickyBoy89/uxnvm/uxn"
+)
+
+// runHeadless runs the program without a display, calling the screen vector
+// once per frame for `frames` frames, or until the machine halts. One of the
+// `script` keys is tapped at the start of every frame, after the Console device
+// has given the program all of its input, and the Audio devices
+// play silently for a sixtieth of a second per frame. The screen is then saved
+// as a PNG to `path`, even if the program faulted, so that the state of the
+// screen can be inspected
+func runHeadless(machine *uxn.Machine, frames int, script []scriptedKey, path string) error {
+	err := runFrames(machine, frames, script)
+
+	system := machine.Devices[systemSlot].(*uxn.SystemDevice)
+	screen := machine.Devices[screenSlot].(*uxn.ScreenDevice)
+	if saveErr := savePNG(path, screen, *system.Palette()); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+func runFrames(machine *uxn.Machine, frames int, script []scriptedKey) error {
+	if err := machine.RunVector(uxn.ProgramStartPage); err != nil {
+		return err
+	}
+	// The program is given all of the arguments and the standard input before
+	// the first frame, so that every run draws the same frames
+	done := machine.StartEventSources()
+	defer machine.CloseEvents()
+	if err := machine.DispatchEventsUntil(done); err != nil {
+		return err
+	}
+	keys := keyTapper{controller: machine.Devices[controllerSlot].(*uxn.ControllerDevice)}
+	audio := newSilentMixer(machine, 60)
+	for frame := 0; frame < frames && !machine.Halted; frame++ {
+		if err := keys.release(); err != nil {
+			return err
+		}
+		if frame < len(script) {
+			if err := keys.tap(script[frame].key, script[frame].char); err != nil {
+				return err
+			}
+		}
+		audio.frame()
+		if err := machine.DispatchEvents(); err != nil {
+			return err
+		}
+		if err := machine.HandleEvent(uxn.Event{Device: screenSlot}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func savePNG(path string, screen *uxn.ScreenDevice, palette uxn.Palette) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(out, screen.Framebuffer.Image(palette)); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
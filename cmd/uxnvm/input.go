@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NickyBoy89/uxnvm/uxn"
+)
+
+// charKey returns the key that types a character, for frontends that are only
+// told which characters were typed
+func charKey(c byte) uxn.Key {
+	switch {
+	case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		return uxn.Key(string(c))
+	case c >= 'A' && c <= 'Z':
+		return uxn.Key(string(c - 'A' + 'a'))
+	case c == '\r' || c == '\n':
+		return uxn.KeyEnter
+	case c == ' ':
+		return uxn.KeySpace
+	case c == '\t':
+		return uxn.KeyTab
+	case c == 0x08:
+		return uxn.KeyBackspace
+	case c == 0x1b:
+		return uxn.KeyEscape
+	}
+	return ""
+}
+
+// A keyTapper presses keys for a single frame, for frontends that are not told
+// when keys are released
+type keyTapper struct {
+	controller *uxn.ControllerDevice
+	held       []uxn.Key
+}
+
+// tap presses a key, typing `char` at the same time if it is not 0
+func (k *keyTapper) tap(key uxn.Key, char byte) error {
+	if key != "" {
+		k.held = append(k.held, key)
+	}
+	return k.controller.Send(uxn.Input{Key: key, Pressed: true, Char: char})
+}
+
+// release lets go of every key tapped since the last release
+func (k *keyTapper) release() error {
+	held := k.held
+	k.held = k.held[:0]
+	for _, key := range held {
+		if err := k.controller.Send(uxn.Input{Key: key}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// A scriptedKey is a key tapped by an input script
+type scriptedKey struct {
+	key  uxn.Key
+	char byte
+}
+
+// parseInputScript reads the keys that are tapped one per frame in headless
+// mode. Every character is typed, and keys can also be tapped by name without
+// typing anything, such as "<up>" or "<ctrl>"
+func parseInputScript(script string) ([]scriptedKey, error) {
+	var keys []scriptedKey
+	for len(script) > 0 {
+		if script[0] == '<' {
+			end := strings.IndexByte(script, '>')
+			if end < 2 {
+				return nil, fmt.Errorf("unfinished key name in input %q", script)
+			}
+			keys = append(keys, scriptedKey{key: uxn.Key(strings.ToLower(script[1:end]))})
+			script = script[end+1:]
+			continue
+		}
+		keys = append(keys, scriptedKey{key: charKey(script[0]), char: script[0]})
+		script = script[1:]
+	}
+	return keys, nil
+}
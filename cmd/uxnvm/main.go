@@ -0,0 +1,160 @@
+// Command uxnvm runs a Uxn rom with the Varvara devices connected
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/NickyBoy89/uxnvm/uxn"
+)
+
+// The slots of the devices that the frontends talk to directly
+const (
+	systemSlot     = 0x0
+	consoleSlot    = 0x1
+	screenSlot     = 0x2
+	audioSlots     = 0x3 // The first of the four Audio devices
+	controllerSlot = 0x8
+	mouseSlot      = 0x9
+	fileSlots      = 0xa // The first of the two File devices
+	datetimeSlot   = 0xc
+)
+
+// The devices connected to each slot of the machine, as laid out by Varvara
+var varvaraDevices = [16]string{
+	"system",     // System
+	"console",    // Console
+	"screen",     // Screen
+	"audio",      // Audio
+	"audio",      // Audio
+	"audio",      // Audio
+	"audio",      // Audio
+	"dummy",      // MIDI
+	"controller", // Controller
+	"mouse",      // Mouse
+	"file",       // File
+	"file",       // File
+	"datetime",   // Datetime
+	"dummy",      // Empty
+	"dummy",      // Reserved
+	"dummy",      // Reserved
+}
+
+func main() {
+	pngPath := flag.String("png", "", "run without a display, and save the screen to this PNG file")
+	frames := flag.Int("frames", 1, "the number of frames to draw before saving the screen with -png")
+	frontend := flag.String("frontend", "cli", "how to display the screen: cli (no display), window or terminal")
+	scale := flag.Int("scale", 1, "how many pixels of the window each pixel of the screen takes up")
+	fileRoot := flag.String("root", ".", "the directory, or zip or tar archive, that the File devices can access")
+	keyMap := flag.String("keymap", "", "which keys press the Controller's buttons, such as \"a=z,b=x,start=enter\"")
+	inputScript := flag.String("input", "", "keys tapped one per frame with -png, where \"<up>\" taps a key by name")
+	fixedTime := flag.String("time", "", "an RFC 3339 time that the Datetime device always reports, instead of the current time")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <rom.rom> [args...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load the rom from disk
+	input, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		panic(err)
+	}
+
+	// Create the Uxn virtual machine
+	machine := uxn.New()
+
+	for slot, name := range varvaraDevices {
+		if _, err := machine.Mount(byte(slot), name); err != nil {
+			panic(err)
+		}
+	}
+
+	// The terminal frontend reads the keyboard from stdin, so the Console
+	// device only receives the arguments. Without a display, all of the input
+	// is read before the first frame, so a terminal, whose input never ends, is
+	// not read at all
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	if *frontend == "terminal" && *pngPath == "" || *pngPath != "" && interactive {
+		machine.AddDevice(consoleSlot, uxn.NewConsoleDevice(strings.NewReader(""), nil, nil))
+	}
+
+	// Keep the File devices inside of the chosen directory or archive
+	files, err := openRoot(*fileRoot)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for slot := byte(fileSlots); slot < fileSlots+2; slot++ {
+		machine.AddDevice(slot, uxn.NewFileDevice(files))
+	}
+
+	if *fixedTime != "" {
+		t, err := time.Parse(time.RFC3339, *fixedTime)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		machine.AddDevice(datetimeSlot, uxn.NewDatetimeDevice(uxn.FixedClock(t)))
+	}
+
+	if *keyMap != "" {
+		keys, err := uxn.ParseKeyMap(*keyMap)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		machine.Devices[controllerSlot].(*uxn.ControllerDevice).Keys = keys
+	}
+	script, err := parseInputScript(*inputScript)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// The rest of the arguments are passed on to the program
+	machine.Devices[consoleSlot].(*uxn.ConsoleDevice).SetArguments(flag.Args()[1:])
+
+	// Load the rom into the create Uxn virtual machine
+	if err := machine.Load(input); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Execute the instructions one at a time
+	switch {
+	case *pngPath != "":
+		err = runHeadless(machine, *frames, script, *pngPath)
+	case *frontend == "window":
+		err = machine.RunVector(uxn.ProgramStartPage)
+		if err == nil && !machine.Halted {
+			err = NewUxnScreen(machine, *scale).Run()
+		}
+	case *frontend == "terminal":
+		err = machine.RunVector(uxn.ProgramStartPage)
+		if err == nil && !machine.Halted {
+			err = NewTerminalScreen(machine).Run()
+		}
+	case *frontend == "cli":
+		err = machine.Run()
+	default:
+		err = fmt.Errorf("unknown frontend %q", *frontend)
+	}
+	machine.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	os.Exit(machine.ExitCode())
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"github.com/NickyBoy89/uxnvm/uxn"
+)
+
+// errHalted is returned from `Update` to stop ebiten once the program halts
+var errHalted = errors.New("halted")
+
+// The keys that have names of their own in `uxn.Key`
+var ebitenKeys = map[ebiten.Key]uxn.Key{
+	ebiten.KeyControlLeft:  uxn.KeyControl,
+	ebiten.KeyControlRight: uxn.KeyControl,
+	ebiten.KeyAltLeft:      uxn.KeyAlt,
+	ebiten.KeyAltRight:     uxn.KeyAlt,
+	ebiten.KeyShiftLeft:    uxn.KeyShift,
+	ebiten.KeyShiftRight:   uxn.KeyShift,
+	ebiten.KeyHome:         uxn.KeyHome,
+	ebiten.KeyArrowUp:      uxn.KeyUp,
+	ebiten.KeyArrowDown:    uxn.KeyDown,
+	ebiten.KeyArrowLeft:    uxn.KeyLeft,
+	ebiten.KeyArrowRight:   uxn.KeyRight,
+	ebiten.KeyEnter:        uxn.KeyEnter,
+	ebiten.KeyEscape:       uxn.KeyEscape,
+	ebiten.KeySpace:        uxn.KeySpace,
+	ebiten.KeyTab:          uxn.KeyTab,
+	ebiten.KeyBackspace:    uxn.KeyBackspace,
+}
+
+// The characters typed by keys that ebiten does not report as input
+var ebitenKeyChars = map[uxn.Key]byte{
+	uxn.KeyEnter:     '\r',
+	uxn.KeyEscape:    0x1b,
+	uxn.KeyTab:       '\t',
+	uxn.KeyBackspace: 0x08,
+}
+
+// ebitenKey names one of ebiten's keys, or returns "" if it has no name
+func ebitenKey(key ebiten.Key) uxn.Key {
+	switch {
+	case key >= ebiten.KeyA && key <= ebiten.KeyZ:
+		return uxn.Key(string(rune('a' + key - ebiten.KeyA)))
+	case key >= ebiten.KeyDigit0 && key <= ebiten.KeyDigit9:
+		return uxn.Key(string(rune('0' + key - ebiten.KeyDigit0)))
+	}
+	return ebitenKeys[key]
+}
+
+// The buttons of the Mouse device pressed by each of ebiten's mouse buttons
+var mouseButtons = map[ebiten.MouseButton]byte{
+	ebiten.MouseButtonLeft:   uxn.MouseLeft,
+	ebiten.MouseButtonMiddle: uxn.MouseMiddle,
+	ebiten.MouseButtonRight:  uxn.MouseRight,
+}
+
+// UxnScreen displays the Screen device in a window, and drives the machine
+// from ebiten's game loop, calling the screen vector once per frame
+type UxnScreen struct {
+	machine    *uxn.Machine
+	system     *uxn.SystemDevice
+	screen     *uxn.ScreenDevice
+	controller *uxn.ControllerDevice
+	mouse      *uxn.MouseDevice
+	// Plays the four Audio devices together
+	mixer *uxn.Mixer
+	// How many pixels of the window each pixel of the screen takes up
+	scale int
+
+	// The image that the framebuffer is copied into, and the palette it was
+	// last drawn with
+	image   *ebiten.Image
+	pixels  []byte
+	palette uxn.Palette
+
+	// The last input that was sent to the program
+	held     map[uxn.Key]bool
+	pressed  []ebiten.Key
+	keyboard []rune
+	err      error
+}
+
+// NewUxnScreen creates a frontend for a machine that has already run its
+// reset vector
+func NewUxnScreen(machine *uxn.Machine, scale int) *UxnScreen {
+	return &UxnScreen{
+		machine:    machine,
+		system:     machine.Devices[systemSlot].(*uxn.SystemDevice),
+		screen:     machine.Devices[screenSlot].(*uxn.ScreenDevice),
+		controller: machine.Devices[controllerSlot].(*uxn.ControllerDevice),
+		mouse:      machine.Devices[mouseSlot].(*uxn.MouseDevice),
+		mixer:      newMixer(machine),
+		scale:      scale,
+		held:       map[uxn.Key]bool{},
+	}
+}
+
+// Run opens the window and runs the program until it halts or the window is
+// closed
+func (us *UxnScreen) Run() error {
+	f := &us.screen.Framebuffer
+	ebiten.SetWindowSize(f.Width*us.scale, f.Height*us.scale)
+	ebiten.SetWindowTitle("uxnvm")
+
+	player, err := audio.NewPlayer(audio.NewContext(uxn.SampleRate), us.mixer)
+	if err != nil {
+		return err
+	}
+	defer player.Close()
+	// The player buffers the mixer's output ahead of time, so keep it small to
+	// hear notes soon after the program plays them
+	player.SetBufferSize(time.Second / 20)
+	player.Play()
+
+	us.machine.StartEventSources()
+	if err := ebiten.RunGame(us); err != nil && err != errHalted {
+		return err
+	}
+	return us.err
+}
+
+func (us *UxnScreen) Update() error {
+	if us.machine.Halted {
+		return errHalted
+	}
+
+	width, height := us.screen.Framebuffer.Width, us.screen.Framebuffer.Height
+
+	if err := us.forwardInput(); err != nil {
+		return us.fail(err)
+	}
+	if err := us.machine.DispatchEvents(); err != nil {
+		return us.fail(err)
+	}
+	if err := us.machine.HandleEvent(uxn.Event{Device: screenSlot}); err != nil {
+		return us.fail(err)
+	}
+
+	// Follow the program when it resizes the screen
+	f := &us.screen.Framebuffer
+	if f.Width != width || f.Height != height {
+		ebiten.SetWindowSize(f.Width*us.scale, f.Height*us.scale)
+	}
+	return nil
+}
+
+// fail remembers why the program stopped, so that it can be reported once the
+// window has closed
+func (us *UxnScreen) fail(err error) error {
+	us.err = err
+	return errHalted
+}
+
+// forwardInput sends the keyboard to the Controller device, and the mouse to
+// the Mouse device, calling their vectors whenever anything changes
+func (us *UxnScreen) forwardInput() error {
+	// Send every key that was pressed or released since the last frame
+	now := map[uxn.Key]bool{}
+	us.pressed = inpututil.AppendPressedKeys(us.pressed[:0])
+	for _, key := range us.pressed {
+		if name := ebitenKey(key); name != "" {
+			now[name] = true
+		}
+	}
+	for key := range us.held {
+		if !now[key] {
+			if err := us.controller.Send(uxn.Input{Key: key}); err != nil {
+				return err
+			}
+		}
+	}
+	for key := range now {
+		if !us.held[key] {
+			if err := us.controller.Send(uxn.Input{Key: key, Pressed: true, Char: ebitenKeyChars[key]}); err != nil {
+				return err
+			}
+		}
+	}
+	us.held = now
+
+	// Every character typed calls the vector once
+	us.keyboard = ebiten.AppendInputChars(us.keyboard[:0])
+	for _, char := range us.keyboard {
+		if char > 0x7f {
+			continue
+		}
+		if err := us.controller.Send(uxn.Input{Char: byte(char)}); err != nil {
+			return err
+		}
+	}
+
+	mouse := us.cursor()
+	for button, bit := range mouseButtons {
+		if ebiten.IsMouseButtonPressed(button) {
+			mouse.Buttons |= bit
+		}
+	}
+	// The wheel scrolls up with positive values, and the Mouse device scrolls
+	// down with them, and only the direction is kept from touchpads
+	scrollX, scrollY := ebiten.Wheel()
+	mouse.ScrollX, mouse.ScrollY = sign(scrollX), -sign(scrollY)
+	return us.mouse.Send(mouse)
+}
+
+// cursor returns the position of the pointer in pixels of the screen
+//
+// ebiten translates the pointer from the window through the size returned by
+// `Layout`, which undoes the scale that the window is drawn at, so it only has
+// to be kept inside of the screen
+func (us *UxnScreen) cursor() uxn.MouseInput {
+	f := &us.screen.Framebuffer
+	x, y := ebiten.CursorPosition()
+	return uxn.MouseInput{X: clamp(x, 0, f.Width-1), Y: clamp(y, 0, f.Height-1)}
+}
+
+func clamp(x, low, high int) int {
+	if x < low {
+		return low
+	}
+	if x > high {
+		return high
+	}
+	return x
+}
+
+func sign(x float64) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+func (us *UxnScreen) Draw(screen *ebiten.Image) {
+	f := &us.screen.Framebuffer
+	palette := *us.system.Palette()
+
+	if us.image == nil || len(us.pixels) != f.Width*f.Height*4 {
+		us.image = ebiten.NewImage(f.Width, f.Height)
+		us.pixels = make([]byte, f.Width*f.Height*4)
+		f.Changed = true
+	}
+
+	if f.Changed || palette != us.palette {
+		for y := 0; y < f.Height; y++ {
+			for x := 0; x < f.Width; x++ {
+				c := palette[f.At(x, y)]
+				i := (x + y*f.Width) * 4
+				us.pixels[i] = c.R
+				us.pixels[i+1] = c.G
+				us.pixels[i+2] = c.B
+				us.pixels[i+3] = c.A
+			}
+		}
+		us.image.ReplacePixels(us.pixels)
+		us.palette = palette
+		f.Changed = false
+	}
+
+	screen.DrawImage(us.image, nil)
+}
+
+func (us *UxnScreen) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return us.screen.Framebuffer.Width, us.screen.Framebuffer.Height
+}
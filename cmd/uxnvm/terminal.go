@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/NickyBoy89/uxnvm/uxn"
+)
+
+// errInterrupted is returned when the terminal frontend is stopped with Ctrl-C
+var errInterrupted = errors.New("interrupted")
+
+// terminalFrameRate is how many times per second the screen vector is called
+const terminalFrameRate = 60
+
+// mousePrefix starts every mouse report sent by the terminal
+var mousePrefix = []byte("\x1b[<")
+
+// maxMouseReport is the longest that a mouse report can be. Longer input is
+// not a report, and is read as keys instead of waiting for the rest of it
+const maxMouseReport = 32
+
+// The escape sequences sent by the terminal for keys that do not type a
+// character. Terminals do not report when a key is released, so each key is
+// held for a single frame
+var terminalKeys = map[string]uxn.Key{
+	"\x1b[H":  uxn.KeyHome,
+	"\x1b[1~": uxn.KeyHome,
+	"\x1b[A":  uxn.KeyUp,
+	"\x1b[B":  uxn.KeyDown,
+	"\x1b[D":  uxn.KeyLeft,
+	"\x1b[C":  uxn.KeyRight,
+	"\x1bOA":  uxn.KeyUp,    // In application mode
+	"\x1bOB":  uxn.KeyDown,  // In application mode
+	"\x1bOD":  uxn.KeyLeft,  // In application mode
+	"\x1bOC":  uxn.KeyRight, // In application mode
+}
+
+// TerminalScreen displays the Screen device in the terminal, drawing two
+// pixels in every character with half blocks, and drives the machine in the
+// same way as `UxnScreen`
+type TerminalScreen struct {
+	machine *uxn.Machine
+	system  *uxn.SystemDevice
+	screen  *uxn.ScreenDevice
+
+	in  *os.File
+	out *bufio.Writer
+
+	// The size of the terminal and the palette that the screen was last drawn
+	// with, which cause a redraw when they change
+	columns, rows int
+	palette       uxn.Palette
+
+	// How many pixels of the screen each character is wide, and half as tall
+	step int
+
+	// The keys pressed during the last frame
+	keys  keyTapper
+	mouse *uxn.MouseDevice
+	// Plays the Audio devices without a sound, as the terminal has no audio
+	audio *silentMixer
+	// The last state of the mouse, in pixels of the screen
+	pointer uxn.MouseInput
+	// The start of a report that was split across reads, which is finished by
+	// the next read
+	pending []byte
+}
+
+// NewTerminalScreen creates a frontend for a machine that has already run its
+// reset vector
+func NewTerminalScreen(machine *uxn.Machine) *TerminalScreen {
+	return &TerminalScreen{
+		machine: machine,
+		system:  machine.Devices[systemSlot].(*uxn.SystemDevice),
+		screen:  machine.Devices[screenSlot].(*uxn.ScreenDevice),
+		in:      os.Stdin,
+		out:     bufio.NewWriter(os.Stdout),
+		step:    1,
+		keys:    keyTapper{controller: machine.Devices[controllerSlot].(*uxn.ControllerDevice)},
+		mouse:   machine.Devices[mouseSlot].(*uxn.MouseDevice),
+		audio:   newSilentMixer(machine, terminalFrameRate),
+	}
+}
+
+// Run takes over the terminal and runs the program until it halts, or Ctrl-C
+// is pressed. The terminal reads the keyboard from stdin, so the Console device
+// should be given some other input
+func (ts *TerminalScreen) Run() error {
+	state, err := term.MakeRaw(int(ts.in.Fd()))
+	if err != nil {
+		return fmt.Errorf("terminal: %w", err)
+	}
+	defer term.Restore(int(ts.in.Fd()), state)
+
+	// Switch to the alternate screen, hide the cursor, and report every
+	// movement of the mouse
+	ts.out.WriteString("\x1b[?1049h\x1b[?25l\x1b[?1003h\x1b[?1006h")
+	defer func() {
+		ts.out.WriteString("\x1b[0m\x1b[?1006l\x1b[?1003l\x1b[?25h\x1b[?1049l")
+		ts.out.Flush()
+	}()
+
+	ts.machine.StartEventSources()
+	keys := make(chan []byte)
+	go readKeys(ts.in, keys)
+
+	ticker := time.NewTicker(time.Second / terminalFrameRate)
+	defer ticker.Stop()
+
+	for !ts.machine.Halted {
+		var input []byte
+	wait:
+		for {
+			select {
+			case chunk, ok := <-keys:
+				if !ok {
+					return nil
+				}
+				input = append(input, chunk...)
+			case <-ticker.C:
+				break wait
+			}
+		}
+
+		if err := ts.forwardInput(input); err != nil {
+			return err
+		}
+		ts.audio.frame()
+		if err := ts.machine.DispatchEvents(); err != nil {
+			return err
+		}
+		if err := ts.machine.HandleEvent(uxn.Event{Device: screenSlot}); err != nil {
+			return err
+		}
+		if err := ts.draw(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readKeys sends everything typed into the terminal to `keys`, until the input
+// is closed
+func readKeys(in io.Reader, keys chan<- []byte) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			keys <- chunk
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// forwardInput releases the keys pressed during the last frame, and then sends
+// the keys typed since then to the Controller device
+func (ts *TerminalScreen) forwardInput(input []byte) error {
+	if err := ts.keys.release(); err != nil {
+		return err
+	}
+	input = append(ts.pending, input...)
+	ts.pending = nil
+	for len(input) > 0 {
+		if bytes.HasPrefix(input, mousePrefix) {
+			n, ok := ts.parseMouse(input)
+			if n == 0 {
+				ts.pending = input
+				return nil
+			}
+			if ok {
+				if err := ts.mouse.Send(ts.pointer); err != nil {
+					return err
+				}
+				ts.pointer.ScrollY = 0
+			}
+			input = input[n:]
+			continue
+		}
+		if string(input) == "\x1b[" {
+			// The start of a report or key that was split across reads
+			ts.pending = input
+			return nil
+		}
+		if input[0] == 0x1b {
+			matched := false
+			for seq, key := range terminalKeys {
+				if len(input) >= len(seq) && string(input[:len(seq)]) == seq {
+					if err := ts.keys.tap(key, 0); err != nil {
+						return err
+					}
+					input = input[len(seq):]
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+		}
+
+		char := input[0]
+		input = input[1:]
+		switch {
+		case char == 0x03: // Ctrl-C
+			return errInterrupted
+		case char == 0x7f: // Most terminals send delete for backspace
+			char = 0x08
+		case char > 0x7f:
+			continue
+		}
+		if err := ts.keys.tap(charKey(char), char); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseMouse reads a mouse report from the start of the input, in the form
+// "\x1b[<button;column;row" followed by 'M' when pressed or 'm' when released,
+// and updates the pointer
+//
+// It returns the length of the report, and whether it was understood. Reports
+// that are not understood should be skipped, and a length of 0 means that the
+// report is not finished yet
+func (ts *TerminalScreen) parseMouse(input []byte) (int, bool) {
+	end := bytes.IndexAny(input, "Mm")
+	switch {
+	case end < 0 && len(input) < maxMouseReport:
+		return 0, false
+	case end < 0 || end > maxMouseReport:
+		// Not a report, so only skip the start of it
+		return len(mousePrefix), false
+	}
+
+	fields := input[len(mousePrefix):end]
+	var button, column, row int
+	if len(bytes.Trim(fields, "0123456789;")) > 0 {
+		return end + 1, false
+	}
+	if _, err := fmt.Sscanf(string(fields), "%d;%d;%d", &button, &column, &row); err != nil {
+		return end + 1, false
+	}
+	pressed := input[end] == 'M'
+
+	// Every character covers `step` pixels across, and twice as many down
+	f := &ts.screen.Framebuffer
+	ts.pointer.X = clamp((column-1)*ts.step, 0, f.Width-1)
+	ts.pointer.Y = clamp((row-1)*2*ts.step, 0, f.Height-1)
+
+	switch {
+	case button&64 != 0: // The wheel, where 64 scrolls up and 65 down
+		if button&1 != 0 {
+			ts.pointer.ScrollY = 1
+		} else {
+			ts.pointer.ScrollY = -1
+		}
+	case button&32 != 0: // Moving without changing the buttons
+	default:
+		bit := [4]byte{uxn.MouseLeft, uxn.MouseMiddle, uxn.MouseRight, 0}[button&3]
+		if pressed {
+			ts.pointer.Buttons |= bit
+		} else {
+			ts.pointer.Buttons &^= bit
+		}
+	}
+	return end + 1, true
+}
+
+// draw redraws the whole screen if anything has changed, shrinking it to fit
+// in the terminal
+func (ts *TerminalScreen) draw() error {
+	f := &ts.screen.Framebuffer
+	palette := *ts.system.Palette()
+
+	columns, rows, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return fmt.Errorf("terminal: %w", err)
+	}
+	resized := columns != ts.columns || rows != ts.rows
+	if !f.Changed && palette == ts.palette && !resized {
+		return nil
+	}
+	ts.columns, ts.rows, ts.palette = columns, rows, palette
+	f.Changed = false
+
+	// Every character is one pixel wide and two pixels tall, so the screen is
+	// shrunk by skipping pixels until it fits
+	step := 1
+	for f.Width/step > columns || (f.Height/step+1)/2 > rows {
+		step++
+	}
+	ts.step = step
+
+	if resized {
+		ts.out.WriteString("\x1b[2J")
+	}
+	ts.out.WriteString("\x1b[H")
+	for y := 0; y+step <= f.Height || y == 0; y += 2 * step {
+		if y > 0 {
+			ts.out.WriteString("\x1b[0m\r\n")
+		}
+		last := [2]byte{0xff, 0xff}
+		for x := 0; x+step <= f.Width; x += step {
+			top := f.At(x, y)
+			bottom := top
+			if y+step < f.Height {
+				bottom = f.At(x, y+step)
+			}
+			if top != last[0] {
+				c := palette[top]
+				fmt.Fprintf(ts.out, "\x1b[38;2;%d;%d;%dm", c.R, c.G, c.B)
+			}
+			if bottom != last[1] {
+				c := palette[bottom]
+				fmt.Fprintf(ts.out, "\x1b[48;2;%d;%d;%dm", c.R, c.G, c.B)
+			}
+			last = [2]byte{top, bottom}
+			ts.out.WriteString("▀")
+		}
+	}
+	ts.out.WriteString("\x1b[0m")
+	return ts.out.Flush()
+}
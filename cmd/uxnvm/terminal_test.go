@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/NickyBoy89/uxnvm/uxn"
+)
+
+// Tests reading the keyboard and mouse from the terminal
+
+func testTerminal(t *testing.T) *TerminalScreen {
+	machine := uxn.New()
+	for slot, name := range varvaraDevices {
+		if _, err := machine.Mount(byte(slot), name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewTerminalScreen(machine)
+}
+
+func TestTerminalMouseSplit(t *testing.T) {
+	ts := testTerminal(t)
+
+	// The report is finished by the next read, and is not typed as keys
+	if err := ts.forwardInput([]byte("\x1b[<0;5;")); err != nil {
+		t.Fatal(err)
+	}
+	if len(ts.keys.held) != 0 || ts.pointer.Buttons != 0 {
+		t.Fatalf("Unfinished report was read as %q, %+v", ts.keys.held, ts.pointer)
+	}
+	if err := ts.forwardInput([]byte("3M")); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := uxn.MouseInput{X: 4, Y: 4, Buttons: uxn.MouseLeft}
+	if ts.pointer != expected || len(ts.keys.held) != 0 {
+		t.Logf("Actual: %+v, keys %q", ts.pointer, ts.keys.held)
+		t.Logf("Expect: %+v", expected)
+		t.Fatal("Pointers differed")
+	}
+}
+
+func TestTerminalMouseMalformed(t *testing.T) {
+	ts := testTerminal(t)
+
+	if err := ts.forwardInput([]byte("\x1b[<0;x;1Mq\x1b[<1;2Mw")); err != nil {
+		t.Fatal(err)
+	}
+	if ts.pointer != (uxn.MouseInput{}) {
+		t.Fatalf("Malformed report moved the pointer to %+v", ts.pointer)
+	}
+	// Only the keys after the reports are typed
+	if len(ts.keys.held) != 2 || ts.keys.held[0] != "q" || ts.keys.held[1] != "w" {
+		t.Fatalf("Typed %q", ts.keys.held)
+	}
+}
@@ -2,11 +2,15 @@ module github.com/NickyBoy89/uxnvm
 
 go 1.19
 
-require github.com/hajimehoshi/ebiten/v2 v2.3.7
+require (
+	github.com/hajimehoshi/ebiten/v2 v2.3.7
+	golang.org/x/term v0.0.0-20220526004731-065cf7ba2467
+)
 
 require (
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20220320163800-277f93cfa958 // indirect
 	github.com/gofrs/flock v0.8.1 // indirect
+	github.com/hajimehoshi/oto/v2 v2.1.0 // indirect
 	github.com/jezek/xgb v1.0.0 // indirect
 	golang.org/x/exp v0.0.0-20190731235908-ec7cb31e5a56 // indirect
 	golang.org/x/image v0.0.0-20220321031419-a8550c1d254a // indirect
@@ -0,0 +1,279 @@
+package uxn
+
+import (
+	"sync"
+)
+
+// SampleRate is the number of samples per second, for each of the left and
+// right channels, that a `Mixer` produces
+const SampleRate = 44100
+
+const (
+	// The period of a note played at its original pitch
+	notePeriod = SampleRate * 0x4000 / 11025
+	// The length of a single step of the envelope, where each of the attack,
+	// decay, sustain and release lasts up to 15 steps
+	adsrStep = SampleRate / 0xf
+	// The loudest that the envelope gets
+	envelopePeak = 0x0888
+)
+
+// advances are how far through a sample each note of the top octave moves per
+// sample played, in 16.16 fixed-point, and are halved for each octave below
+var advances = [12]uint32{
+	0x80000, 0x879c8, 0x8facd, 0x9837f, 0xa1451, 0xaadc1,
+	0xb504f, 0xbfc88, 0xcb2ff, 0xd7450, 0xe411f, 0xf1a1c,
+}
+
+// The AudioDevice plays a single note at a time, from a sample stored in
+// memory. Varvara has four of them, which are played together by a `Mixer`
+//
+// The program sets the envelope (0x8), sample length (0xa), sample address
+// (0xc) and volume (0xe), and then starts the note by writing its pitch to
+// port 0xf. When the note ends, the Audio vector is called
+//
+// Reference: https://wiki.xxiivv.com/site/varvara.html#audio
+type AudioDevice struct {
+	Ports
+
+	// Held while reading or changing the note, which is played on the host's
+	// audio goroutine
+	lock sync.Mutex
+	// A copy of the sample, so that the program can change memory while it
+	// plays
+	sample []byte
+	// The position in the sample, and how far it moves per sample played
+	position        uint16
+	count, advance  uint32
+	period          uint32
+	repeat          bool
+	volume          [2]int32
+	age, a, d, s, r uint32
+}
+
+// NewAudioDevice creates an Audio device that is not playing anything
+func NewAudioDevice() *AudioDevice {
+	return &AudioDevice{}
+}
+
+// Reset stops the current note, and clears the ports
+func (a *AudioDevice) Reset() {
+	a.Ports.Reset()
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.advance = 0
+}
+
+func (a *AudioDevice) DeviceRead8(port byte) byte {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	switch port {
+	case 0x2: // The position in the sample
+		return byte(a.position >> 8)
+	case 0x3:
+		return byte(a.position)
+	case 0x4: // How loud each channel currently is
+		return a.loudness()
+	default:
+		return a.Data[port]
+	}
+}
+
+func (a *AudioDevice) DeviceWrite8(port, data byte) {
+	a.Data[port] = data
+	if port == 0xf {
+		a.start()
+	}
+}
+
+// start begins playing the note described by the ports
+func (a *AudioDevice) start() {
+	length := int(a.Peek16(0xa))
+	addr := int(a.Peek16(0xc))
+	// Samples stop at the end of memory, instead of wrapping around
+	if addr+length > len(a.Machine.Memory) {
+		length = len(a.Machine.Memory) - addr
+	}
+	sample := make([]byte, length)
+	copy(sample, a.Machine.Memory[addr:addr+length])
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.sample = sample
+	a.volume = [2]int32{int32(a.Data[0xe] >> 4), int32(a.Data[0xe] & 0xf)}
+	a.repeat = a.Data[0xf]&0x80 == 0
+	pitch := a.Data[0xf] & 0x7f
+	if pitch >= 108 || length == 0 {
+		a.advance = 0
+		return
+	}
+	a.advance = advances[pitch%12] >> (8 - pitch/12)
+
+	adsr := a.Peek16(0x8)
+	a.a = adsrStep * uint32(adsr>>12)
+	a.d = adsrStep*uint32(adsr>>8&0xf) + a.a
+	a.s = adsrStep*uint32(adsr>>4&0xf) + a.d
+	a.r = adsrStep*uint32(adsr&0xf) + a.s
+	a.age = 0
+	a.position = 0
+	a.count = 0
+	if length <= 0x100 {
+		// Short samples are a single cycle of a wave, which is repeated
+		a.period = notePeriod * 337 / 2 / uint32(length)
+	} else {
+		a.period = notePeriod
+	}
+}
+
+// envelope returns the volume of the note when it has played for `age`
+// samples, without changing the note
+func (a *AudioDevice) envelope(age uint32) int32 {
+	switch {
+	case a.r == 0:
+		return envelopePeak
+	case age < a.a:
+		return int32(envelopePeak * age / a.a)
+	case age < a.d:
+		return int32(envelopePeak / 2 * (2*a.d - a.a - age) / (a.d - a.a))
+	case age < a.s:
+		return envelopePeak / 2
+	case age < a.r:
+		return int32(envelopePeak / 2 * (a.r - age) / (a.r - a.s))
+	}
+	return 0
+}
+
+// released returns true once the note has played for longer than its
+// envelope lasts
+func (a *AudioDevice) released() bool {
+	return a.r != 0 && a.age >= a.r
+}
+
+// loudness returns how loud the left and right channels are, in the high and
+// low nibbles
+func (a *AudioDevice) loudness() byte {
+	if a.advance == 0 || a.period == 0 || a.released() {
+		return 0
+	}
+	var sum [2]int32
+	for i := range sum {
+		if a.volume[i] == 0 {
+			continue
+		}
+		sum[i] = 1 + a.envelope(a.age)*a.volume[i]/0x800
+		if sum[i] > 0xf {
+			sum[i] = 0xf
+		}
+	}
+	return byte(sum[0]<<4 | sum[1])
+}
+
+// render adds the note to `out`, which holds pairs of left and right samples,
+// and returns true if the note ended while rendering
+func (a *AudioDevice) render(out []int32) bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.advance == 0 || a.period == 0 {
+		return false
+	}
+	length := uint16(len(a.sample))
+	for i := 0; i+1 < len(out); i += 2 {
+		if a.released() {
+			a.advance = 0
+			break
+		}
+		a.count += a.advance
+		a.position += uint16(a.count / a.period)
+		a.count %= a.period
+		if a.position >= length {
+			if !a.repeat {
+				a.advance = 0
+				break
+			}
+			a.position %= length
+		}
+		s := int32(int8(a.sample[a.position]+0x80)) * a.envelope(a.age)
+		a.age++
+		out[i] += s * a.volume[0] / 0x180
+		out[i+1] += s * a.volume[1] / 0x180
+	}
+	return a.advance == 0
+}
+
+// A Mixer plays Audio devices together, as a single stream of signed 16-bit
+// stereo samples at `SampleRate`, which can be given to any audio library
+//
+// Mixing is safe to do on the host's audio goroutine while the machine runs.
+// When a note ends, an event is posted to call the Audio vector of its device
+type Mixer struct {
+	voices []mixerVoice
+	mixed  []int32
+	out    []int16
+}
+
+// A mixerVoice is an Audio device, along with where to post its events. These
+// are found when the mixer is created, as the machine belongs to its own
+// goroutine
+type mixerVoice struct {
+	device  *AudioDevice
+	machine *Machine
+	slot    byte
+	ok      bool
+}
+
+// NewMixer creates a mixer that plays `voices` together. It has to be called
+// on the same goroutine as the machine, once the devices are connected to it
+func NewMixer(voices ...*AudioDevice) *Mixer {
+	m := &Mixer{}
+	for _, device := range voices {
+		slot, ok := device.Slot()
+		m.voices = append(m.voices, mixerVoice{device: device, machine: device.Machine, slot: slot, ok: ok})
+	}
+	return m
+}
+
+// Mix fills `samples` with the next part of the stream, as pairs of left and
+// right samples
+func (m *Mixer) Mix(samples []int16) {
+	if cap(m.mixed) < len(samples) {
+		m.mixed = make([]int32, len(samples))
+	}
+	mixed := m.mixed[:len(samples)]
+	for i := range mixed {
+		mixed[i] = 0
+	}
+
+	for _, voice := range m.voices {
+		if voice.device.render(mixed) && voice.ok {
+			// Audio can not wait for the machine, so the vector is skipped if
+			// the machine is too busy to handle it
+			voice.machine.TryPost(Event{Device: voice.slot})
+		}
+	}
+
+	for i, s := range mixed {
+		if s > 0x7fff {
+			s = 0x7fff
+		} else if s < -0x8000 {
+			s = -0x8000
+		}
+		samples[i] = int16(s)
+	}
+}
+
+// Read fills `p` with the next part of the stream, with each sample stored in
+// little-endian order, so that the mixer can be used as an `io.Reader`
+func (m *Mixer) Read(p []byte) (int, error) {
+	n := len(p) / 4 * 2
+	if cap(m.out) < n {
+		m.out = make([]int16, n)
+	}
+	out := m.out[:n]
+	m.Mix(out)
+	for i, s := range out {
+		p[2*i] = byte(s)
+		p[2*i+1] = byte(uint16(s) >> 8)
+	}
+	return n * 2, nil
+}
@@ -0,0 +1,139 @@
+package uxn
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// Tests playing notes with the Audio devices and mixing them together
+
+// audioMachine creates a machine with an Audio device at 0x3, whose vector
+// pushes 0xaa, and which is playing a square wave at full volume
+func audioMachine(pitch byte) (*Machine, *AudioDevice) {
+	m := New()
+	audio := NewAudioDevice()
+	m.AddDevice(0x3, audio)
+	m.Load([]byte{0x80, 0xaa, 0x00}) // LIT aa BRK
+	DeviceWrite16(audio, 0x0, ProgramStartPage)
+	copy(m.Memory[0x2000:], []byte{0xff, 0xff, 0x00, 0x00})
+	DeviceWrite16(audio, 0xa, 4)
+	DeviceWrite16(audio, 0xc, 0x2000)
+	audio.DeviceWrite8(0xe, 0xff)
+	audio.DeviceWrite8(0xf, pitch)
+	return m, audio
+}
+
+func TestAudioNoteEnds(t *testing.T) {
+	// The sample is not repeated, so it ends after a single cycle
+	m, audio := audioMachine(0x80 | 60)
+	mixer := NewMixer(audio)
+
+	samples := make([]int16, 2*SampleRate/10)
+	mixer.Mix(samples)
+
+	if samples[0] == 0 {
+		t.Fatal("Nothing was played")
+	}
+	if audio.DeviceRead8(0x4) != 0 {
+		t.Fatal("Note was still playing after it ended")
+	}
+
+	if err := m.DispatchEvents(); err != nil {
+		t.Fatal(err)
+	}
+	expected := CreateStack([]byte{0xaa})
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Audio vector was not called")
+	}
+}
+
+func TestAudioNoteRepeats(t *testing.T) {
+	m, audio := audioMachine(60)
+	mixer := NewMixer(audio)
+
+	samples := make([]int16, 2*SampleRate/10)
+	mixer.Mix(samples)
+
+	if audio.DeviceRead8(0x4) == 0 {
+		t.Fatal("Repeating note stopped playing")
+	}
+	m.DispatchEvents()
+	if m.WorkingStack.Pointer != 0 {
+		t.Fatal("Audio vector was called before the note ended")
+	}
+}
+
+func TestAudioLoudnessRead(t *testing.T) {
+	m, audio := audioMachine(60)
+	DeviceWrite16(audio, 0x8, 0x0001) // Only a short release
+	audio.DeviceWrite8(0xf, 60)
+	audio.age = audio.r
+
+	// Reading the loudness after the envelope has ended does not stop the note,
+	// which is left to the mixer so that the vector is still called
+	if audio.DeviceRead8(0x4) != 0 {
+		t.Fatal("Released note was still loud")
+	}
+	NewMixer(audio).Mix(make([]int16, 2))
+	m.DispatchEvents()
+	if m.WorkingStack.Pointer != 1 {
+		t.Fatal("Audio vector was not called when the envelope ended")
+	}
+}
+
+func TestAudioSampleEndOfMemory(t *testing.T) {
+	_, audio := audioMachine(60)
+	DeviceWrite16(audio, 0xa, 0x10)
+	DeviceWrite16(audio, 0xc, 0xfffc)
+	audio.DeviceWrite8(0xf, 60)
+
+	if len(audio.sample) != 4 {
+		t.Fatalf("Sample was %v bytes long, expected 4", len(audio.sample))
+	}
+}
+
+func TestAudioVolume(t *testing.T) {
+	_, audio := audioMachine(60)
+	// Only play on the left channel
+	audio.DeviceWrite8(0xe, 0xf0)
+	audio.DeviceWrite8(0xf, 60)
+	mixer := NewMixer(audio)
+
+	samples := make([]int16, 64)
+	mixer.Mix(samples)
+
+	for i := 0; i < len(samples); i += 2 {
+		if samples[i] == 0 || samples[i+1] != 0 {
+			t.Fatalf("Sample %v was %v, %v", i/2, samples[i], samples[i+1])
+		}
+	}
+	if audio.DeviceRead8(0x4) != 0xf0 {
+		t.Fatalf("Loudness was %.2x", audio.DeviceRead8(0x4))
+	}
+}
+
+func TestMixerRead(t *testing.T) {
+	_, first := audioMachine(60)
+	_, second := audioMachine(60)
+
+	samples := make([]int16, 64)
+	NewMixer(first).Mix(samples)
+
+	// Two of the same note are twice as loud, but never past the limit
+	buf := make([]byte, 2*len(samples))
+	n, err := NewMixer(first, second).Read(buf)
+	if err != nil || n != len(buf) {
+		t.Fatalf("Read %v bytes, %v", n, err)
+	}
+	for i := range samples {
+		expected := int32(samples[i]) * 2
+		if expected > 0x7fff {
+			expected = 0x7fff
+		}
+		if actual := int16(binary.LittleEndian.Uint16(buf[2*i:])); int32(actual) != expected {
+			t.Fatalf("Sample %v was %v, expected %v", i, actual, expected)
+		}
+	}
+}
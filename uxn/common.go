@@ -0,0 +1,252 @@
+package uxn
+
+import (
+	"bufio"
+	"io"
+	"os"
+)
+
+// DummyDevice only exists for protyping purposes, and will always return `0`
+// when read from, as well as not do anything when written to
+type DummyDevice struct {
+	Ports
+}
+
+// NewDummyDevice creates a device that ignores the program
+func NewDummyDevice() *DummyDevice {
+	return &DummyDevice{}
+}
+
+func (d *DummyDevice) DeviceRead8(port byte) byte {
+	return 0
+}
+
+func (d *DummyDevice) DeviceWrite8(port, data byte) {
+}
+
+// The SystemDevice controls the execution of the Uxn system
+// Reference: https://wiki.xxiivv.com/site/varvara.html#system
+type SystemDevice struct {
+	Ports
+	// Where the stacks are printed when the program asks for them
+	debug io.Writer
+	// The colors decoded from the color ports, which are shared with the
+	// frontends drawing the screen
+	palette *Palette
+}
+
+// NewSystemDevice creates a System device. When the program writes to the
+// debug port, the stacks are printed to `debug`, or to os.Stderr if it is nil
+func NewSystemDevice(debug io.Writer) *SystemDevice {
+	if debug == nil {
+		debug = os.Stderr
+	}
+	s := &SystemDevice{debug: debug}
+	s.decodePalette()
+	return s
+}
+
+// Palette returns the colors that the program has written to the color ports.
+// The same palette is returned every time, and is updated whenever the program
+// writes to the color ports, so that frontends can keep hold of it
+func (s *SystemDevice) Palette() *Palette {
+	if s.palette == nil {
+		s.decodePalette()
+	}
+	return s.palette
+}
+
+// decodePalette updates the palette from the color ports
+func (s *SystemDevice) decodePalette() {
+	if s.palette == nil {
+		s.palette = new(Palette)
+	}
+	*s.palette = DecodePalette(s.Peek16(0x8), s.Peek16(0xa), s.Peek16(0xc))
+}
+
+// Reset clears the ports, which also sets every color of the palette to black
+func (s *SystemDevice) Reset() {
+	s.Ports.Reset()
+	s.decodePalette()
+}
+
+// DeviceRead8 reads the stack pointers from the machine. A System device that
+// is not attached to a machine only gives back what was written to its ports
+func (s *SystemDevice) DeviceRead8(port byte) byte {
+	port &= 0x0f
+	if s.Machine == nil {
+		return s.Data[port]
+	}
+	switch port {
+	case 0x4:
+		return s.Machine.WorkingStack.Pointer
+	case 0x5:
+		return s.Machine.ReturnStack.Pointer
+	default:
+		return s.Data[port]
+	}
+}
+
+// DeviceWrite8 controls the machine. A System device that is not attached to
+// a machine only stores the byte and updates its palette
+func (s *SystemDevice) DeviceWrite8(port, data byte) {
+	port &= 0x0f
+	s.Data[port] = data
+	if port >= 0x8 && port <= 0xd { // The red, green and blue channels of the palette
+		s.decodePalette()
+	}
+	if s.Machine == nil {
+		return
+	}
+	switch port {
+	case 0x0, 0x1: // The error vector, called by the machine on a fault
+	case 0x2: // The address of an expansion command, run once it is fully written
+	case 0x3:
+		s.Machine.expand(s.Peek16(0x2))
+	case 0x4:
+		s.Machine.WorkingStack.Pointer = data
+	case 0x5:
+		s.Machine.ReturnStack.Pointer = data
+	case 0xe: // Prints the contents of the stacks
+		// Output written before this comes first, as in uxncli. Errors are
+		// kept by the devices, and returned when the vector ends
+		s.Machine.flush()
+		s.Machine.Inspect(s.debug)
+	case 0xf: // Halts the program when a non-zero state is written
+		if data != 0 {
+			s.Machine.Halted = true
+		}
+	}
+}
+
+// The kinds of input that the Console device gives the program, which are
+// stored in its type port (0x7) when its vector is called
+const (
+	ConsoleNone     = 0x0
+	ConsoleStandard = 0x1 // A byte read from the standard input
+	ConsoleArgument = 0x2 // A byte of one of the command-line arguments
+	ConsoleSpacer   = 0x3 // The end of an argument, with more to follow
+	ConsoleEnd      = 0x4 // The end of the arguments, or of the standard input
+)
+
+// The ConsoleDevice controls input and output from the host
+// Reference: https://wiki.xxiivv.com/site/varvara.html#console
+type ConsoleDevice struct {
+	Ports
+	// Where the program's input is read from
+	stdin io.Reader
+	// Where the program's output and errors are written, which are buffered
+	// until the machine flushes them
+	stdout, stderr *bufio.Writer
+	// The command-line arguments given to the program before its input
+	args []string
+}
+
+// NewConsoleDevice creates a Console device that reads the program's input
+// from `stdin`, and writes its output and errors to `stdout` and `stderr`. Any
+// of them that are nil are replaced with the standard streams of the process
+func NewConsoleDevice(stdin io.Reader, stdout, stderr io.Writer) *ConsoleDevice {
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	return &ConsoleDevice{
+		stdin:  stdin,
+		stdout: bufio.NewWriter(stdout),
+		stderr: bufio.NewWriter(stderr),
+	}
+}
+
+// SetArguments gives the program command-line arguments, which it receives
+// through the Console vector before any input. The type port is set while the
+// reset vector runs, so that the program can tell if there are any
+func (c *ConsoleDevice) SetArguments(args []string) {
+	c.args = args
+	c.Reset()
+}
+
+// Reset clears the ports, apart from the type port if there are arguments
+func (c *ConsoleDevice) Reset() {
+	c.Ports.Reset()
+	if len(c.args) > 0 {
+		c.Data[0x7] = ConsoleArgument
+	}
+}
+
+func (c *ConsoleDevice) DeviceWrite8(port, data byte) {
+	c.Data[port] = data
+	switch port {
+	case 0x8:
+		c.stdout.WriteByte(data)
+	case 0x9:
+		c.stderr.WriteByte(data)
+	}
+}
+
+// Flush writes out everything that the program has written so far
+func (c *ConsoleDevice) Flush() error {
+	err := c.stdout.Flush()
+	if errErr := c.stderr.Flush(); err == nil {
+		err = errErr
+	}
+	return err
+}
+
+// Close flushes anything that the program has written since the last vector
+func (c *ConsoleDevice) Close() error {
+	return c.Flush()
+}
+
+// Listen posts an event for every byte of the arguments and the standard
+// input, which the program receives in the read port (0x2)
+//
+// The arguments come first, with the type set to `ConsoleArgument`, and each
+// followed by a newline with the type set to `ConsoleSpacer`, or `ConsoleEnd`
+// for the last one. Then every byte of the standard input is posted with the
+// type set to `ConsoleStandard`, and once it ends, a last event is posted with
+// the type set to `ConsoleEnd`
+//
+// Programs that have not set the Console vector by the time the reset vector
+// finishes do not want any input, so nothing is read for them
+func (c *ConsoleDevice) Listen(slot byte, vector uint16, post func(Event)) {
+	if vector == 0 {
+		return
+	}
+
+	for i, arg := range c.args {
+		for j := 0; j < len(arg); j++ {
+			post(c.input(slot, arg[j], ConsoleArgument))
+		}
+		if i == len(c.args)-1 {
+			post(c.input(slot, '\n', ConsoleEnd))
+		} else {
+			post(c.input(slot, '\n', ConsoleSpacer))
+		}
+	}
+
+	in := bufio.NewReader(c.stdin)
+	for {
+		data, err := in.ReadByte()
+		if err != nil {
+			break
+		}
+		post(c.input(slot, data, ConsoleStandard))
+	}
+	post(c.input(slot, 0, ConsoleEnd))
+}
+
+// input creates an event that gives the program a byte of input
+func (c *ConsoleDevice) input(slot, data, kind byte) Event {
+	return Event{
+		Device: slot,
+		Update: func() {
+			c.Data[0x2] = data
+			c.Data[0x7] = kind
+		},
+	}
+}
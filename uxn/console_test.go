@@ -0,0 +1,150 @@
+package uxn
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests the behavior of the Console device
+
+// consoleEcho sets the Console vector to a handler that pushes the read port
+// and the type port for every byte of input
+var consoleEcho = []byte{
+	0xa0, 0x01, 0x07, 0x80, 0x10, 0x37, 0x00, // LIT2 0107 LIT 10 DEO2 BRK
+	0x80, 0x12, 0x16, 0x80, 0x17, 0x16, 0x00, // LIT 12 DEI LIT 17 DEI BRK
+}
+
+func TestConsoleInput(t *testing.T) {
+	var m Machine
+	console := NewConsoleDevice(strings.NewReader("ab"), nil, nil)
+	m.AddDevice(0x1, console)
+	m.Load(consoleEcho)
+
+	if err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CreateStack([]byte{'a', ConsoleStandard, 'b', ConsoleStandard, 0x00, ConsoleEnd})
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+// unreadable fails the test if the Console device reads from it
+type unreadable struct {
+	t *testing.T
+}
+
+func (u unreadable) Read(p []byte) (int, error) {
+	u.t.Fatal("Console read input without a vector set")
+	return 0, nil
+}
+
+func TestConsoleInputWithoutVector(t *testing.T) {
+	var m Machine
+	console := NewConsoleDevice(unreadable{t}, nil, nil)
+	m.AddDevice(0x1, console)
+	m.Load([]byte{0x00}) // BRK
+
+	if err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsoleArguments(t *testing.T) {
+	var m Machine
+	console := NewConsoleDevice(strings.NewReader(""), nil, nil)
+	console.SetArguments([]string{"hi", "x"})
+	m.AddDevice(0x1, console)
+	m.Load(consoleEcho)
+
+	if err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CreateStack([]byte{
+		'h', ConsoleArgument, 'i', ConsoleArgument, '\n', ConsoleSpacer,
+		'x', ConsoleArgument, '\n', ConsoleEnd,
+		0x00, ConsoleEnd,
+	})
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestConsoleArgumentsType(t *testing.T) {
+	var m Machine
+	console := NewConsoleDevice(strings.NewReader(""), nil, nil)
+	console.SetArguments([]string{"hi"})
+	m.AddDevice(0x1, console)
+	m.Load([]byte{0x80, 0x17, 0x16, 0x00}) // LIT 17 DEI BRK
+	m.RunVector(ProgramStartPage)
+
+	expected := CreateStack([]byte{ConsoleArgument})
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestConsoleOutput(t *testing.T) {
+	var m Machine
+	var stdout, stderr bytes.Buffer
+	m.AddDevice(0x1, NewConsoleDevice(strings.NewReader(""), &stdout, &stderr))
+	m.Load([]byte{
+		0x80, 'h', 0x80, 0x18, 0x17, // LIT 'h' LIT 18 DEO
+		0x80, 'i', 0x80, 0x18, 0x17, // LIT 'i' LIT 18 DEO
+		0x80, '!', 0x80, 0x19, 0x17, // LIT '!' LIT 19 DEO
+		0x00, // BRK
+	})
+
+	// Output is buffered until the vector ends
+	for i := 0; i < 6; i++ {
+		m.Step()
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("Output %q was written before the vector ended", stdout.String())
+	}
+
+	// Finish the rest of the vector
+	m.RunVector(m.ProgramCounter)
+
+	if stdout.String() != "hi" || stderr.String() != "!" {
+		t.Logf("Actual: %q %q", stdout.String(), stderr.String())
+		t.Logf("Expect: %q %q", "hi", "!")
+		t.Fatal("Output differed")
+	}
+}
+
+func TestConsoleHaltWithFullQueue(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	var m Machine
+	m.AddDevice(0x0, NewSystemDevice(nil))
+	m.AddDevice(0x1, NewConsoleDevice(strings.NewReader(strings.Repeat("a", 1000)), nil, nil))
+	m.Load([]byte{
+		0xa0, 0x01, 0x07, 0x80, 0x10, 0x37, 0x00, // LIT2 0107 LIT 10 DEO2 BRK
+		0x80, 0x01, 0x80, 0x0f, 0x17, 0x00, // LIT 01 LIT 0f DEO BRK
+	})
+	if err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Console device stops listening once the program has halted, instead
+	// of waiting forever for room in the queue
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%v goroutines were left running", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,157 @@
+package uxn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The buttons of the Controller device, as the bits of its button port (0x2)
+const (
+	ButtonA      byte = 0x01
+	ButtonB      byte = 0x02
+	ButtonSelect byte = 0x04
+	ButtonStart  byte = 0x08
+	ButtonUp     byte = 0x10
+	ButtonDown   byte = 0x20
+	ButtonLeft   byte = 0x40
+	ButtonRight  byte = 0x80
+)
+
+// buttonNames are the names of the buttons used by `ParseKeyMap`
+var buttonNames = map[string]byte{
+	"a":      ButtonA,
+	"b":      ButtonB,
+	"select": ButtonSelect,
+	"start":  ButtonStart,
+	"up":     ButtonUp,
+	"down":   ButtonDown,
+	"left":   ButtonLeft,
+	"right":  ButtonRight,
+}
+
+// A Key names a key on the host's keyboard, so that every frontend describes
+// keys in the same way. Letters and digits are named by themselves, such as
+// "z" or "1", and the rest of the keys are named by the constants below
+type Key string
+
+const (
+	KeyControl   Key = "ctrl"
+	KeyAlt       Key = "alt"
+	KeyShift     Key = "shift"
+	KeyHome      Key = "home"
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyLeft      Key = "left"
+	KeyRight     Key = "right"
+	KeyEnter     Key = "enter"
+	KeyEscape    Key = "escape"
+	KeySpace     Key = "space"
+	KeyTab       Key = "tab"
+	KeyBackspace Key = "backspace"
+)
+
+// A KeyMap decides which button of the Controller device is pressed by each
+// key
+type KeyMap map[Key]byte
+
+// DefaultKeyMap is the layout used by the reference emulator
+var DefaultKeyMap = KeyMap{
+	KeyControl: ButtonA,
+	KeyAlt:     ButtonB,
+	KeyShift:   ButtonSelect,
+	KeyHome:    ButtonStart,
+	KeyUp:      ButtonUp,
+	KeyDown:    ButtonDown,
+	KeyLeft:    ButtonLeft,
+	KeyRight:   ButtonRight,
+}
+
+// Copy returns a key map with the same keys, which can be changed without
+// affecting the original
+func (k KeyMap) Copy() KeyMap {
+	keys := make(KeyMap, len(k))
+	for key, button := range k {
+		keys[key] = button
+	}
+	return keys
+}
+
+// ParseKeyMap reads a key map written as comma-separated "button=key" pairs,
+// such as "a=z,b=x,start=enter". Buttons that are not mentioned keep their
+// keys from `DefaultKeyMap`
+func ParseKeyMap(s string) (KeyMap, error) {
+	keys := DefaultKeyMap.Copy()
+	for _, pair := range strings.Split(s, ",") {
+		name, key, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		button, known := buttonNames[strings.ToLower(name)]
+		if !ok || !known || key == "" {
+			return nil, fmt.Errorf("uxn: can not map %q, expected button=key", pair)
+		}
+		for k, b := range keys {
+			if b == button {
+				delete(keys, k)
+			}
+		}
+		keys[Key(strings.ToLower(key))] = button
+	}
+	return keys, nil
+}
+
+// An Input is a single change to the keyboard, reported by a frontend
+type Input struct {
+	// The key that was pressed or released, if any
+	Key Key
+	// Whether the key is now held down
+	Pressed bool
+	// The character that was typed, or 0 if none was
+	Char byte
+}
+
+// The ControllerDevice gives the program the buttons held down on a gamepad,
+// which is emulated with the keyboard, and the characters that are typed
+// Reference: https://wiki.xxiivv.com/site/varvara.html#controller
+type ControllerDevice struct {
+	Ports
+	// Which button each key presses
+	Keys KeyMap
+}
+
+// NewControllerDevice creates a Controller device that presses buttons with
+// the keys in `keys`, or with a copy of `DefaultKeyMap` if it is nil
+func NewControllerDevice(keys KeyMap) *ControllerDevice {
+	if keys == nil {
+		keys = DefaultKeyMap.Copy()
+	}
+	return &ControllerDevice{Keys: keys}
+}
+
+// Send updates the ports from the input, and calls the Controller vector if
+// a button changed or a character was typed. A typed character is only in the
+// key port (0x3) while the vector runs
+//
+// It has to be called on the same goroutine as the machine, such as from a
+// frontend's loop
+func (c *ControllerDevice) Send(in Input) error {
+	buttons := c.Data[0x2]
+	if button, ok := c.Keys[in.Key]; ok {
+		if in.Pressed {
+			buttons |= button
+		} else {
+			buttons &^= button
+		}
+	}
+	if buttons == c.Data[0x2] && in.Char == 0 {
+		return nil
+	}
+
+	c.Data[0x2] = buttons
+	c.Data[0x3] = in.Char
+	defer func() {
+		c.Data[0x3] = 0
+	}()
+	slot, ok := c.Slot()
+	if !ok {
+		return nil
+	}
+	return c.Machine.HandleEvent(Event{Device: slot})
+}
@@ -0,0 +1,82 @@
+package uxn
+
+import (
+	"testing"
+)
+
+// Tests the buttons and keys of the Controller device
+
+// controllerEcho sets the Controller vector to a handler that pushes the button
+// port and the key port every time it is called
+var controllerEcho = []byte{
+	0xa0, 0x01, 0x07, 0x80, 0x80, 0x37, 0x00, // LIT2 0107 LIT 80 DEO2 BRK
+	0x80, 0x82, 0x16, 0x80, 0x83, 0x16, 0x00, // LIT 82 DEI LIT 83 DEI BRK
+}
+
+func TestControllerInput(t *testing.T) {
+	var m Machine
+	controller := NewControllerDevice(nil)
+	m.AddDevice(0x8, controller)
+	m.Load(controllerEcho)
+	m.RunVector(ProgramStartPage)
+
+	inputs := []Input{
+		{Key: KeyControl, Pressed: true},
+		{Key: KeyUp, Pressed: true},
+		{Key: "q", Pressed: true}, // Not mapped, so the vector is not called
+		{Char: 'q'},
+		{Key: KeyControl, Pressed: false},
+	}
+	for _, in := range inputs {
+		if err := controller.Send(in); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := CreateStack([]byte{
+		ButtonA, 0x00,
+		ButtonA | ButtonUp, 0x00,
+		ButtonA | ButtonUp, 'q',
+		ButtonUp, 0x00,
+	})
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if controller.DeviceRead8(0x3) != 0 {
+		t.Fatal("Typed character was left in the key port")
+	}
+}
+
+func TestControllerKeysNotShared(t *testing.T) {
+	first := NewControllerDevice(nil)
+	first.Keys["z"] = ButtonA
+	delete(first.Keys, KeyControl)
+
+	if _, ok := DefaultKeyMap["z"]; ok || DefaultKeyMap[KeyControl] != ButtonA {
+		t.Fatal("Changing a controller's keys changed the default key map")
+	}
+	if second := NewControllerDevice(nil); second.Keys[KeyControl] != ButtonA {
+		t.Fatal("Changing a controller's keys changed another controller")
+	}
+}
+
+func TestParseKeyMap(t *testing.T) {
+	keys, err := ParseKeyMap("a=z, start=Enter")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keys["z"] != ButtonA || keys[KeyEnter] != ButtonStart || keys[KeyAlt] != ButtonB {
+		t.Fatalf("Unexpected key map %v", keys)
+	}
+	if _, ok := keys[KeyControl]; ok {
+		t.Fatal("Replaced key was still mapped")
+	}
+
+	if _, err := ParseKeyMap("jump=space"); err == nil {
+		t.Fatal("Unknown button was accepted")
+	}
+}
@@ -0,0 +1,66 @@
+package uxn
+
+import (
+	"time"
+)
+
+// A Clock tells the Datetime device the current time
+type Clock func() time.Time
+
+// FixedClock creates a clock that is always at the same time, so that programs
+// reading the time behave the same way every time they run
+func FixedClock(t time.Time) Clock {
+	return func() time.Time {
+		return t
+	}
+}
+
+// The DatetimeDevice gives the program the current date and time in the
+// host's time zone
+// Reference: https://wiki.xxiivv.com/site/varvara.html#datetime
+type DatetimeDevice struct {
+	Ports
+	clock Clock
+}
+
+// NewDatetimeDevice creates a Datetime device that reads the time from
+// `clock`, or from the host's clock if it is nil
+func NewDatetimeDevice(clock Clock) *DatetimeDevice {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &DatetimeDevice{clock: clock}
+}
+
+func (d *DatetimeDevice) DeviceRead8(port byte) byte {
+	now := d.clock()
+	switch port {
+	case 0x0:
+		return byte(now.Year() >> 8)
+	case 0x1:
+		return byte(now.Year())
+	case 0x2: // Months count from 0
+		return byte(now.Month() - 1)
+	case 0x3:
+		return byte(now.Day())
+	case 0x4:
+		return byte(now.Hour())
+	case 0x5:
+		return byte(now.Minute())
+	case 0x6:
+		return byte(now.Second())
+	case 0x7: // Days of the week count from Sunday
+		return byte(now.Weekday())
+	case 0x8: // Days of the year count from 0
+		return byte((now.YearDay() - 1) >> 8)
+	case 0x9:
+		return byte(now.YearDay() - 1)
+	case 0xa:
+		if now.IsDST() {
+			return 1
+		}
+		return 0
+	default:
+		return d.Data[port]
+	}
+}
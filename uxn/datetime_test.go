@@ -0,0 +1,43 @@
+package uxn
+
+import (
+	"testing"
+	"time"
+)
+
+// Tests reading the time through the Datetime device
+
+func TestDatetime(t *testing.T) {
+	var m Machine
+	clock := FixedClock(time.Date(2022, time.March, 4, 13, 45, 30, 0, time.UTC))
+	m.AddDevice(0xc, NewDatetimeDevice(clock))
+
+	var ports [11]byte
+	for port := range ports {
+		ports[port] = m.Devices[0xc].DeviceRead8(byte(port))
+	}
+
+	// 2022, March (2), 4th, 13:45:30, Friday (5), 63rd day (62), not DST
+	expected := [11]byte{0x07, 0xe6, 2, 4, 13, 45, 30, 5, 0, 62, 0}
+	if ports != expected {
+		t.Logf("Actual: %v", HexPrint(ports[:]))
+		t.Logf("Expect: %v", HexPrint(expected[:]))
+		t.Fatal("Ports differed")
+	}
+}
+
+func TestDatetimeFromProgram(t *testing.T) {
+	var m Machine
+	clock := FixedClock(time.Date(1999, time.December, 31, 23, 59, 59, 0, time.UTC))
+	m.AddDevice(0xc, NewDatetimeDevice(clock))
+	m.Load([]byte{0x80, 0xc0, 0x36, 0x80, 0xc9, 0x16, 0x00}) // LIT c0 DEI2 LIT c9 DEI BRK
+	m.RunVector(ProgramStartPage)
+
+	// 1999 (07cf), and the last day of the year (364)
+	expected := CreateStack([]byte{0x07, 0xcf, 0x6c})
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
@@ -0,0 +1,207 @@
+package uxn
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// A Device represents an external device connected to a Uxn CPU
+//
+// It has 16 bytes of internal "IO" memory that can be read and written to with
+// the `DeviceWrite` and `DeviceRead` set of methods, and the behavior of reading
+// and writing data from the device is completely defined by the device
+//
+// Most devices embed `Ports`, which provides the IO memory and default
+// implementations of every method
+//
+// Reference: https://wiki.xxiivv.com/site/varvara.html
+type Device interface {
+	// DeviceRead8 is called when the program reads a single byte from one of
+	// the device's ports (0x00-0x0f)
+	DeviceRead8(port byte) byte
+	// DeviceWrite8 is called when the program writes a single byte to one of
+	// the device's ports (0x00-0x0f)
+	DeviceWrite8(port, data byte)
+	// Attach is called when the device is connected to a machine, because
+	// devices can define addresses in main memory to call when they are changed
+	Attach(u *Machine)
+	// Reset returns the device to the state it was in when it was attached
+	Reset()
+	// Close releases anything the device holds on the host
+	Close() error
+}
+
+// An EventSource is a device that posts events on its own, such as when input
+// arrives from the host
+//
+// `Run` calls Listen on its own goroutine once the program has started. Listen
+// should keep posting events until it has no more to give, and then return, so
+// that `Run` knows when the program can no longer receive input
+//
+// Listen is given the device's slot and its vector as they were when it was
+// started. The machine and the ports belong to the machine's goroutine, so
+// Listen should not read them itself, and should only change the ports from
+// the `Update` of the events that it posts
+type EventSource interface {
+	Device
+	Listen(slot byte, vector uint16, post func(Event))
+}
+
+// A Flusher is a device that buffers its output to the host. The machine
+// flushes it whenever a vector ends, either with a BRK or by halting
+type Flusher interface {
+	Device
+	Flush() error
+}
+
+// Ports is the 16 bytes of IO memory that every device has, and is meant to be
+// embedded into devices. On its own, it is a device that stores everything
+// written to it
+type Ports struct {
+	// The virtual machine that the device is attached to
+	Machine *Machine
+	// A device has 16 IO ports (0x00-0x0f) that can be written to and read from
+	Data [16]byte
+}
+
+// DeviceRead8 returns the last byte written to the port
+func (p *Ports) DeviceRead8(port byte) byte {
+	return p.Data[port&0x0f]
+}
+
+// DeviceWrite8 stores a byte in the port
+func (p *Ports) DeviceWrite8(port, data byte) {
+	p.Data[port&0x0f] = data
+}
+
+// Attach links the ports to the machine
+func (p *Ports) Attach(u *Machine) {
+	p.Machine = u
+}
+
+// Reset clears all of the ports
+func (p *Ports) Reset() {
+	p.Data = [16]byte{}
+}
+
+// Close does nothing, because the ports do not hold anything on the host
+func (p *Ports) Close() error {
+	return nil
+}
+
+// ports gives access to the ports embedded in any device, for `Slot`
+func (p *Ports) ports() *Ports {
+	return p
+}
+
+// Slot returns the slot that the device holding the ports is connected to, or
+// false if it is not connected to a machine
+func (p *Ports) Slot() (byte, bool) {
+	if p.Machine == nil {
+		return 0, false
+	}
+	for slot, device := range p.Machine.Devices {
+		if d, ok := device.(interface{ ports() *Ports }); ok && d.ports() == p {
+			return byte(slot), true
+		}
+	}
+	return 0, false
+}
+
+// Peek16 reads a short stored in two of the ports, without going through the
+// device that owns them
+func (p *Ports) Peek16(port byte) uint16 {
+	return uint16(p.Data[port&0x0f])<<8 + uint16(p.Data[(port+1)&0x0f])
+}
+
+// Poke16 stores a short in two of the ports, without going through the device
+// that owns them
+func (p *Ports) Poke16(port byte, data uint16) {
+	p.Data[port&0x0f] = byte(data >> 8)
+	p.Data[(port+1)&0x0f] = byte(data)
+}
+
+// DeviceWrite16 writes a single short to the device at a given port
+//
+// A `port` is a byte where the first 4 bits are the device being accessed
+// (Ex: 0x1) and the second 4 bits are the IO port being accessed in the device
+// (Ex: 0x08). For example, writing to port (0x18) means the "Write" port of the
+// Console device. Since the device has already been chosen, only the last 4 bits
+// are used
+func DeviceWrite16(d Device, port byte, data uint16) {
+	d.DeviceWrite8(port&0x0f, byte(data>>8))
+	d.DeviceWrite8((port+1)&0x0f, byte(data))
+}
+
+// DeviceRead16 reads a single short from the device at a given port
+func DeviceRead16(d Device, port byte) uint16 {
+	return uint16(d.DeviceRead8(port&0x0f))<<8 + uint16(d.DeviceRead8((port+1)&0x0f))
+}
+
+// DeviceVector returns the address stored in the device's vector port (0x00),
+// which is called by the machine whenever the device posts an event
+func DeviceVector(d Device) uint16 {
+	return DeviceRead16(d, 0x00)
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]func() Device{}
+)
+
+// RegisterDevice makes a kind of device available to `Mount` under `name`.
+// Every call to `Mount` uses `factory` to create a new device, so that
+// machines never share devices
+//
+// It panics if a device is already registered with the same name
+func RegisterDevice(name string, factory func() Device) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("uxn: device %q registered twice", name))
+	}
+	registry[name] = factory
+}
+
+// RegisteredDevices returns the sorted names of every registered device
+func RegisteredDevices() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Mount creates a new device from the ones registered with `RegisterDevice`,
+// and connects it to the machine at the given slot (0x0-0xf)
+func (u *Machine) Mount(slot byte, name string) (Device, error) {
+	if slot > 0x0f {
+		return nil, fmt.Errorf("uxn: no device slot %#x", slot)
+	}
+	registryLock.RLock()
+	factory, ok := registry[name]
+	registryLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("uxn: no device registered as %q", name)
+	}
+	device := factory()
+	u.AddDevice(slot, device)
+	return device, nil
+}
+
+func init() {
+	RegisterDevice("dummy", func() Device { return NewDummyDevice() })
+	RegisterDevice("ports", func() Device { return &Ports{} })
+	RegisterDevice("system", func() Device { return NewSystemDevice(nil) })
+	RegisterDevice("console", func() Device { return NewConsoleDevice(nil, nil, nil) })
+	RegisterDevice("screen", func() Device { return NewScreenDevice() })
+	RegisterDevice("audio", func() Device { return NewAudioDevice() })
+	RegisterDevice("controller", func() Device { return NewControllerDevice(nil) })
+	RegisterDevice("mouse", func() Device { return NewMouseDevice() })
+	RegisterDevice("file", func() Device { return NewFileDevice(DirFS(".")) })
+	RegisterDevice("datetime", func() Device { return NewDatetimeDevice(nil) })
+}
@@ -0,0 +1,135 @@
+package uxn
+
+import (
+	"testing"
+)
+
+// Tests connecting devices to the virtual machine
+
+// A testDevice counts the calls to its lifecycle methods, and posts events when
+// it is listened to
+type testDevice struct {
+	Ports
+	resets, closes int
+	events         []Event
+}
+
+func (d *testDevice) Reset() {
+	d.Ports.Reset()
+	d.resets++
+}
+
+func (d *testDevice) Close() error {
+	d.closes++
+	return nil
+}
+
+func (d *testDevice) Listen(slot byte, vector uint16, post func(Event)) {
+	for _, event := range d.events {
+		post(event)
+	}
+}
+
+// registerTestDevice registers a device for the length of a single test, so
+// that the test can run more than once
+func registerTestDevice(t *testing.T, name string, factory func() Device) {
+	RegisterDevice(name, factory)
+	t.Cleanup(func() {
+		registryLock.Lock()
+		defer registryLock.Unlock()
+		delete(registry, name)
+	})
+}
+
+func TestMountRegisteredDevice(t *testing.T) {
+	registerTestDevice(t, "test-mount", func() Device { return &testDevice{} })
+
+	var m Machine
+	first, err := m.Mount(0x7, "test-mount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Devices[0x7] != first {
+		t.Fatal("Device was not mounted at its slot")
+	}
+	if first.(*testDevice).Machine != &m {
+		t.Fatal("Device was not attached to the machine")
+	}
+
+	second, _ := m.Mount(0x8, "test-mount")
+	if first == second {
+		t.Fatal("Mounted devices were shared")
+	}
+}
+
+func TestMountUnknownDevice(t *testing.T) {
+	var m Machine
+	if _, err := m.Mount(0x7, "does-not-exist"); err == nil {
+		t.Fatal("Mounting an unregistered device succeeded")
+	}
+	if _, err := m.Mount(0x10, "dummy"); err == nil {
+		t.Fatal("Mounting past the last slot succeeded")
+	}
+}
+
+func TestDeviceLifecycle(t *testing.T) {
+	var m Machine
+	device := &testDevice{}
+	m.AddDevice(0x7, device)
+	m.Load([]byte{0x80, 0x12, 0x80, 0x72, 0x17}) // LIT 12 LIT 72 DEO
+	m.Run()
+
+	if device.Data[0x2] != 0x12 {
+		t.Fatalf("Expected 12 in port 0x2, got %.2x", device.Data[0x2])
+	}
+
+	m.Reset()
+	if device.resets != 1 || device.Data[0x2] != 0 {
+		t.Fatal("Device was not reset")
+	}
+
+	m.Close()
+	if device.closes != 1 {
+		t.Fatal("Device was not closed")
+	}
+}
+
+func TestRunListensToEventSources(t *testing.T) {
+	var m Machine
+	device := &testDevice{}
+	m.AddDevice(0x7, device)
+	m.Load([]byte{
+		0xa0, 0x01, 0x09, 0x80, 0x70, 0x37, 0x00, // LIT2 0109 LIT 70 DEO2 BRK
+		0x00, 0x00, // Padding
+		0x80, 0x34, 0x00, // LIT 34 BRK
+	})
+	device.events = []Event{{Device: 0x7}, {Device: 0x7}}
+
+	if err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CreateStack([]byte{0x34, 0x34})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack)
+		t.Logf("Expect: %v", expected)
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestPortsSlot(t *testing.T) {
+	var m Machine
+	device := &testDevice{}
+
+	if _, ok := device.Slot(); ok {
+		t.Fatal("Device had a slot before being connected")
+	}
+
+	m.AddDevice(0x7, NewDummyDevice())
+	m.AddDevice(0xb, device)
+
+	if slot, ok := device.Slot(); !ok || slot != 0xb {
+		t.Fatalf("Device was found at slot %#x, %v", slot, ok)
+	}
+}
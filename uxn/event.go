@@ -0,0 +1,130 @@
+package uxn
+
+// The number of events that can be waiting before `Post` blocks
+const eventQueueSize = 64
+
+// An Event is posted by a device when the machine needs to call its vector,
+// such as when a key is pressed or a new frame should be drawn
+type Event struct {
+	// The index of the device whose vector is called
+	Device byte
+	// Update is called right before the vector runs, on the same goroutine as
+	// the machine, so that the device can fill in its ports without racing the
+	// running program
+	Update func()
+}
+
+func (u *Machine) eventQueue() chan Event {
+	u.eventsOnce.Do(func() {
+		u.events = make(chan Event, eventQueueSize)
+		u.closing = make(chan struct{})
+	})
+	return u.events
+}
+
+// Post queues an event to be handled by the machine, waiting for room in the
+// queue if it is full. It is safe to call from any goroutine, and the event is
+// dropped if the queue has been closed
+func (u *Machine) Post(event Event) {
+	queue := u.eventQueue()
+	u.closeLock.RLock()
+	defer u.closeLock.RUnlock()
+	if u.closed {
+		return
+	}
+	select {
+	case queue <- event:
+	case <-u.closing:
+	}
+}
+
+// TryPost queues an event without waiting, and returns false if the queue is
+// full or has been closed. It is safe to call from any goroutine, and is meant
+// for devices that are driven by the host in real time, such as audio
+func (u *Machine) TryPost(event Event) bool {
+	queue := u.eventQueue()
+	u.closeLock.RLock()
+	defer u.closeLock.RUnlock()
+	if u.closed {
+		return false
+	}
+	select {
+	case queue <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// CloseEvents tells the machine that no more events will be posted, so `Run`
+// returns once the remaining ones have been handled. Closing the queue more
+// than once does nothing
+func (u *Machine) CloseEvents() {
+	queue := u.eventQueue()
+	u.closeOnce.Do(func() {
+		close(u.closing)
+		u.closeLock.Lock()
+		defer u.closeLock.Unlock()
+		u.closed = true
+		close(queue)
+	})
+}
+
+// HandleEvent updates the device that posted the event, and then runs the
+// device's vector until it reaches a BRK. Devices without a vector set are
+// only updated
+func (u *Machine) HandleEvent(event Event) error {
+	if event.Update != nil {
+		event.Update()
+	}
+	device := u.Devices[event.Device&0x0f]
+	if device == nil {
+		return nil
+	}
+	vector := DeviceVector(device)
+	if vector == 0 {
+		return nil
+	}
+	return u.RunVector(vector)
+}
+
+// DispatchEvents handles every event that is currently queued without waiting
+// for new ones, for hosts that drive the machine from their own loop instead
+// of calling `Run`
+func (u *Machine) DispatchEvents() error {
+	for !u.Halted {
+		select {
+		case event, ok := <-u.eventQueue():
+			if !ok {
+				return nil
+			}
+			if err := u.HandleEvent(event); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// DispatchEventsUntil handles events as they are posted, waiting for new ones,
+// until `done` is closed and every event queued by then has been handled, or
+// the machine halts. It is used with the channel from `StartEventSources` to
+// give the program all of its input before doing anything else
+func (u *Machine) DispatchEventsUntil(done <-chan struct{}) error {
+	for !u.Halted {
+		select {
+		case event, ok := <-u.eventQueue():
+			if !ok {
+				return nil
+			}
+			if err := u.HandleEvent(event); err != nil {
+				return err
+			}
+		case <-done:
+			return u.DispatchEvents()
+		}
+	}
+	return nil
+}
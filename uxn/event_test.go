@@ -0,0 +1,107 @@
+package uxn
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests that devices can call the program through their vectors
+
+func TestRunCallsVector(t *testing.T) {
+	var m Machine
+	m.AddDevice(0x1, &Ports{})
+	m.Load([]byte{
+		0x80, 0x12, 0x00, // LIT 12 BRK
+		0x80, 0x34, 0x00, // LIT 34 BRK
+	})
+
+	m.Post(Event{
+		Device: 0x1,
+		Update: func() {
+			DeviceWrite16(m.Devices[0x1], 0x00, 0x0103)
+		},
+	})
+	m.CloseEvents()
+
+	if err := m.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CreateStack([]byte{0x12, 0x34})
+
+	if m.WorkingStack.Data != expected.Data {
+		t.Logf("Actual: %v", m.WorkingStack.Data)
+		t.Logf("Expect: %v", expected.Data)
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestEventWithoutVector(t *testing.T) {
+	var m Machine
+	m.AddDevice(0x1, NewDummyDevice())
+	m.Load([]byte{0x00}) // BRK
+
+	updated := false
+	m.Post(Event{
+		Device: 0x1,
+		Update: func() {
+			updated = true
+		},
+	})
+
+	if err := m.DispatchEvents(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !updated {
+		t.Fatal("Device was not updated")
+	}
+	if m.ProgramCounter != ProgramStartPage {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Fatal("A vector was called without being set")
+	}
+}
+
+func TestPostAfterClose(t *testing.T) {
+	var m Machine
+	m.CloseEvents()
+
+	// Posting to a closed queue drops the event instead of panicking
+	m.Post(Event{Device: 0x1})
+	if m.TryPost(Event{Device: 0x1}) {
+		t.Fatal("Posted to a closed queue")
+	}
+}
+
+func TestCloseWakesPost(t *testing.T) {
+	var m Machine
+	for i := 0; i < eventQueueSize; i++ {
+		m.Post(Event{Device: 0x1})
+	}
+
+	posted := make(chan struct{})
+	go func() {
+		m.Post(Event{Device: 0x1}) // Waits for room in the full queue
+		close(posted)
+	}()
+	m.CloseEvents()
+	<-posted
+}
+
+func TestDispatchEventsUntil(t *testing.T) {
+	var m Machine
+	m.AddDevice(0x1, NewConsoleDevice(strings.NewReader("ab"), nil, nil))
+	m.Load(consoleEcho)
+	m.RunVector(ProgramStartPage)
+
+	// Every byte of input is handled before it returns
+	if err := m.DispatchEventsUntil(m.StartEventSources()); err != nil {
+		t.Fatal(err)
+	}
+	expected := CreateStack([]byte{'a', ConsoleStandard, 'b', ConsoleStandard, 0x00, ConsoleEnd})
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
@@ -0,0 +1,61 @@
+package uxn
+
+import "fmt"
+
+// A Fault is returned when an instruction cannot be completed, such as when a
+// stack overflows or a value is divided by zero
+//
+// It wraps the `UxnError` that caused it, so it can be matched with
+// `errors.Is(err, ErrUnderflow)`, and the details of the fault can be recovered
+// with `errors.As`
+type Fault struct {
+	// What went wrong
+	Kind UxnError
+	// The instruction that faulted
+	Instruction byte
+	// The address of the instruction that faulted
+	ProgramCounter uint16
+	// Copies of the stacks at the time of the fault
+	WorkingStack, ReturnStack Stack
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("%v, by instruction %.2x at 0x%.4x", f.Kind, f.Instruction, f.ProgramCounter)
+}
+
+func (f *Fault) Unwrap() error {
+	return f.Kind
+}
+
+// catch hands a fault to the System vector, which is how programs handle their
+// own errors. As in the Varvara spec, the working stack is replaced with the
+// address of the faulting instruction, the instruction, and the error code
+//
+// It returns false when the fault cannot be caught, because the System vector
+// is not set or the fault is not one that the spec defines
+//
+// Reference: https://wiki.xxiivv.com/site/varvara.html#system
+func (u *Machine) catch(f *Fault) bool {
+	switch f.Kind {
+	case ErrUnderflow, ErrOverflow, ErrDivByZero:
+	default:
+		return false
+	}
+
+	system := u.Devices[0x0]
+	if system == nil {
+		return false
+	}
+	vector := DeviceVector(system)
+	if vector == 0 {
+		return false
+	}
+
+	u.WorkingStack.Data[0] = byte(f.ProgramCounter >> 8)
+	u.WorkingStack.Data[1] = byte(f.ProgramCounter)
+	u.WorkingStack.Data[2] = f.Instruction
+	u.WorkingStack.Data[3] = byte(f.Kind)
+	u.WorkingStack.Pointer = 4
+	u.ProgramCounter = vector
+	return true
+}
@@ -0,0 +1,133 @@
+package uxn
+
+import (
+	"errors"
+	"testing"
+)
+
+// Tests that faults are returned from the virtual machine instead of panicking
+
+func TestUnderflowFault(t *testing.T) {
+	var m Machine
+	m.Load([]byte{0x02}) // POP
+	err := m.Step()
+
+	if !errors.Is(err, ErrUnderflow) {
+		t.Fatalf("Expected an underflow, got %v", err)
+	}
+
+	var fault *Fault
+	if !errors.As(err, &fault) {
+		t.Fatalf("Expected a *Fault, got %T", err)
+	}
+	if fault.Instruction != 0x02 {
+		t.Fatalf("Expected instruction 02, got %.2x", fault.Instruction)
+	}
+	if fault.ProgramCounter != ProgramStartPage {
+		t.Fatalf("Expected fault at 0x%.4x, got 0x%.4x", ProgramStartPage, fault.ProgramCounter)
+	}
+}
+
+func TestOverflowFault(t *testing.T) {
+	var m Machine
+	m.WorkingStack.Pointer = 0xff
+	m.Load([]byte{0x80, 0x12}) // LIT 12
+	err := m.Step()
+
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("Expected an overflow, got %v", err)
+	}
+}
+
+func TestDivByZeroFault(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x12, 0x00})
+	m.Load([]byte{0x1b}) // DIV
+	err := m.Step()
+
+	if !errors.Is(err, ErrDivByZero) {
+		t.Fatalf("Expected a division by zero, got %v", err)
+	}
+
+	var fault *Fault
+	if !errors.As(err, &fault) {
+		t.Fatalf("Expected a *Fault, got %T", err)
+	}
+	expected := CreateStack([]byte{0x12, 0x00})
+	if fault.WorkingStack.String() != expected.String() || m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v, %v", fault.WorkingStack.String(), m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestNoDeviceFault(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x18})
+	m.Load([]byte{0x16}) // DEI
+	err := m.Step()
+
+	if !errors.Is(err, ErrNoDevice) {
+		t.Fatalf("Expected a missing device, got %v", err)
+	}
+}
+
+func TestRunStopsOnFault(t *testing.T) {
+	var m Machine
+	m.Load([]byte{0x80, 0x01, 0x80, 0x00, 0x1b}) // LIT 01 LIT 00 DIV
+	err := m.Run()
+
+	if !errors.Is(err, ErrDivByZero) {
+		t.Fatalf("Expected a division by zero, got %v", err)
+	}
+}
+
+func TestFullStackFault(t *testing.T) {
+	var m Machine
+	m.WorkingStack.Pointer = 0xfe
+	m.Load([]byte{0xa0, 0x12, 0x34}) // LIT2 12 34
+	err := m.Step()
+
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("Expected an overflow, got %v", err)
+	}
+}
+
+func TestSystemVectorCatchesFault(t *testing.T) {
+	var m Machine
+	m.AddDevice(0x0, NewSystemDevice(nil))
+	DeviceWrite16(m.Devices[0x0], 0x00, 0x0110)
+	m.Load([]byte{0x02})  // POP
+	m.Poke8(0x0110, 0x00) // BRK
+
+	if err := m.RunVector(ProgramStartPage); err != nil {
+		t.Fatalf("Expected the fault to be caught, got %v", err)
+	}
+
+	expected := CreateStack([]byte{0x01, 0x00, 0x02, byte(ErrUnderflow)})
+
+	if m.WorkingStack.Data != expected.Data || m.WorkingStack.Pointer != 4 {
+		t.Logf("Actual: %v", m.WorkingStack.Data)
+		t.Logf("Expect: %v", expected.Data)
+		t.Fatal("Stacks differed")
+	}
+
+	if m.Halted {
+		t.Fatal("Caught fault halted the machine")
+	}
+}
+
+func TestFaultHaltsWithoutSystemVector(t *testing.T) {
+	var m Machine
+	m.AddDevice(0x0, NewSystemDevice(nil))
+	m.Load([]byte{0x02}) // POP
+	err := m.RunVector(ProgramStartPage)
+
+	if !errors.Is(err, ErrUnderflow) {
+		t.Fatalf("Expected an underflow, got %v", err)
+	}
+
+	if !m.Halted {
+		t.Fatal("Uncaught fault did not halt the machine")
+	}
+}
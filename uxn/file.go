@@ -0,0 +1,263 @@
+package uxn
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// What a File device is currently doing with its file
+type fileState int
+
+const (
+	fileIdle fileState = iota
+	fileReading
+	fileWriting
+	fileListing
+)
+
+// The FileDevice reads and writes files in a file system, which is usually a
+// directory on the host that the program can not leave
+//
+// The program chooses a file by writing the address of its name to the name
+// port (0x8), and then starts reading or writing with the read (0xc) and write
+// (0xe) ports, which copy the number of bytes in the length port (0xa). The
+// number of bytes copied is stored in the success port (0x2), which is 0 when
+// the operation failed. Reading a directory gives a listing of its contents,
+// with one "size name" entry per line
+//
+// Writing and deleting only work when the file system is a `WritableFS`
+//
+// Reference: https://wiki.xxiivv.com/site/varvara.html#file
+type FileDevice struct {
+	Ports
+	// Where every file is read from and written to
+	fsys fs.FS
+
+	// The name of the file chosen by the program, which is empty when no
+	// file has been chosen
+	name   string
+	state  fileState
+	reader fs.File
+	writer io.WriteCloser
+	// The entries of the directory being listed that have not been read yet
+	entries []string
+}
+
+// NewFileDevice creates a File device that can only access the files inside of
+// `fsys`, such as a directory opened with `DirFS`
+func NewFileDevice(fsys fs.FS) *FileDevice {
+	return &FileDevice{fsys: fsys}
+}
+
+// Reset closes the current file, and clears the ports
+func (f *FileDevice) Reset() {
+	f.Ports.Reset()
+	f.close()
+	f.name = ""
+}
+
+// Close closes the current file
+func (f *FileDevice) Close() error {
+	return f.close()
+}
+
+func (f *FileDevice) DeviceWrite8(port, data byte) {
+	f.Data[port] = data
+	switch port {
+	case 0x5: // Stat
+		f.Poke16(0x2, f.stat(f.memory(f.Peek16(0x4))))
+	case 0x6: // Delete
+		f.Poke16(0x2, f.delete())
+	case 0x9: // Name
+		f.Poke16(0x2, f.open(f.readName(f.Peek16(0x8))))
+	case 0xd: // Read
+		f.Poke16(0x2, f.read(f.memory(f.Peek16(0xc))))
+	case 0xf: // Write
+		f.Poke16(0x2, f.write(f.memory(f.Peek16(0xe)), f.Data[0x7] != 0))
+	}
+}
+
+// memory returns the part of memory starting at `addr` that the program
+// wants to use, which is as long as the length port, but never runs past the
+// end of memory
+func (f *FileDevice) memory(addr uint16) []byte {
+	end := int(addr) + int(f.Peek16(0xa))
+	if end > BankSize {
+		end = BankSize
+	}
+	return f.Machine.Memory[addr:end]
+}
+
+// readName reads the null-terminated name stored in memory at `addr`
+func (f *FileDevice) readName(addr uint16) string {
+	name := f.Machine.Memory[addr:]
+	for i, c := range name {
+		if c == 0 {
+			return string(name[:i])
+		}
+	}
+	return string(name)
+}
+
+// open chooses the file that the next operations use, closing the last one
+func (f *FileDevice) open(name string) uint16 {
+	f.close()
+	// Cleaning the name as an absolute path removes any ".." that would leave
+	// the root of the file system
+	f.name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if f.name == "" {
+		f.name = "."
+	}
+	return 0
+}
+
+// close stops reading or writing the current file
+func (f *FileDevice) close() error {
+	var err error
+	if f.reader != nil {
+		err = f.reader.Close()
+		f.reader = nil
+	}
+	if f.writer != nil {
+		err = f.writer.Close()
+		f.writer = nil
+	}
+	f.entries = nil
+	f.state = fileIdle
+	return err
+}
+
+// read copies the next part of the file into `dest`, or the next entries if
+// the file is a directory
+func (f *FileDevice) read(dest []byte) uint16 {
+	if f.name == "" {
+		return 0
+	}
+	if f.state != fileReading && f.state != fileListing {
+		f.close()
+		info, err := fs.Stat(f.fsys, f.name)
+		if err != nil {
+			return 0
+		}
+		if info.IsDir() {
+			entries, err := fs.ReadDir(f.fsys, f.name)
+			if err != nil {
+				return 0
+			}
+			for _, entry := range entries {
+				info, err := entry.Info()
+				if err != nil {
+					continue
+				}
+				f.entries = append(f.entries, listingEntry(info))
+			}
+			f.state = fileListing
+		} else {
+			reader, err := f.fsys.Open(f.name)
+			if err != nil {
+				return 0
+			}
+			f.reader = reader
+			f.state = fileReading
+		}
+	}
+
+	if f.state == fileListing {
+		// Only whole entries are read, and the rest are left for the next read
+		n := 0
+		for len(f.entries) > 0 && n+len(f.entries[0]) <= len(dest) {
+			n += copy(dest[n:], f.entries[0])
+			f.entries = f.entries[1:]
+		}
+		return uint16(n)
+	}
+
+	n, err := io.ReadFull(f.reader, dest)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return 0
+	}
+	return uint16(n)
+}
+
+// listingEntry formats a single line of a directory listing, with the size of
+// the file in hex, or dashes for a directory
+func listingEntry(info fs.FileInfo) string {
+	if info.IsDir() {
+		return fmt.Sprintf("---- %v/\n", info.Name())
+	}
+	if info.Size() > 0xffff {
+		return fmt.Sprintf("???? %v\n", info.Name())
+	}
+	return fmt.Sprintf("%.4x %v\n", info.Size(), info.Name())
+}
+
+// write copies `src` to the file, either replacing what was in it, or adding
+// to the end of it when `appending` is set
+func (f *FileDevice) write(src []byte, appending bool) uint16 {
+	writable, ok := f.fsys.(WritableFS)
+	if f.name == "" || !ok {
+		return 0
+	}
+	if f.state != fileWriting {
+		f.close()
+		writer, err := writable.OpenWriter(f.name, appending)
+		if err != nil {
+			return 0
+		}
+		f.writer = writer
+		f.state = fileWriting
+	}
+
+	n, err := f.writer.Write(src)
+	if err != nil {
+		return 0
+	}
+	return uint16(n)
+}
+
+// stat fills `dest` with the size of the file in hex, padded to the length of
+// `dest`. It is filled with '-' for a directory, '?' when the size does not
+// fit, and '!' when the file does not exist
+func (f *FileDevice) stat(dest []byte) uint16 {
+	if f.name == "" || len(dest) == 0 {
+		return 0
+	}
+	info, err := fs.Stat(f.fsys, f.name)
+	switch {
+	case err != nil:
+		fillBytes(dest, '!')
+	case info.IsDir():
+		fillBytes(dest, '-')
+	case len(dest) < 16 && info.Size() >= 1<<(4*len(dest)):
+		fillBytes(dest, '?')
+	default:
+		size := info.Size()
+		for i := len(dest) - 1; i >= 0; i-- {
+			dest[i] = "0123456789abcdef"[size&0xf]
+			size >>= 4
+		}
+	}
+	return uint16(len(dest))
+}
+
+func fillBytes(dest []byte, c byte) {
+	for i := range dest {
+		dest[i] = c
+	}
+}
+
+// delete removes the file, returning 1 if it was removed
+func (f *FileDevice) delete() uint16 {
+	writable, ok := f.fsys.(WritableFS)
+	if f.name == "" || !ok {
+		return 0
+	}
+	f.close()
+	if err := writable.Remove(f.name); err != nil {
+		return 0
+	}
+	return 1
+}
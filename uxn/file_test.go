@@ -0,0 +1,175 @@
+package uxn
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests reading and writing files through the File device
+
+// fileMachine creates a machine with a File device at 0xa, and `name` chosen
+// as its file
+func fileMachine(root, name string) (*Machine, *FileDevice) {
+	return fsMachine(DirFS(root), name)
+}
+
+// fsMachine is the same as `fileMachine`, but for any file system
+func fsMachine(fsys fs.FS, name string) (*Machine, *FileDevice) {
+	m := New()
+	file := NewFileDevice(fsys)
+	m.AddDevice(0xa, file)
+	copy(m.Memory[0x1000:], name+"\x00")
+	DeviceWrite16(file, 0x8, 0x1000)
+	return m, file
+}
+
+func TestFileWriteRead(t *testing.T) {
+	root := t.TempDir()
+	m, file := fileMachine(root, "hello.txt")
+
+	copy(m.Memory[0x2000:], "hello")
+	DeviceWrite16(file, 0xa, 5)
+	DeviceWrite16(file, 0xe, 0x2000)
+	if file.Peek16(0x2) != 5 {
+		t.Fatalf("Wrote %v bytes", file.Peek16(0x2))
+	}
+
+	// Choosing the file again and appending continues from the end of it
+	DeviceWrite16(file, 0x8, 0x1000)
+	file.DeviceWrite8(0x7, 1)
+	copy(m.Memory[0x2000:], "!")
+	DeviceWrite16(file, 0xa, 1)
+	DeviceWrite16(file, 0xe, 0x2000)
+
+	contents, err := os.ReadFile(filepath.Join(root, "hello.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != "hello!" {
+		t.Fatalf("File contained %q", contents)
+	}
+
+	// Reading stops at the end of the file
+	DeviceWrite16(file, 0x8, 0x1000)
+	DeviceWrite16(file, 0xa, 0x10)
+	DeviceWrite16(file, 0xc, 0x3000)
+	if file.Peek16(0x2) != 6 || string(m.Memory[0x3000:0x3006]) != "hello!" {
+		t.Fatalf("Read %v bytes: %q", file.Peek16(0x2), m.Memory[0x3000:0x3006])
+	}
+}
+
+func TestFileListing(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "a.txt"), make([]byte, 0x12), 0o644)
+	os.Mkdir(filepath.Join(root, "sub"), 0o755)
+	m, file := fileMachine(root, "")
+
+	// Only whole entries are read
+	DeviceWrite16(file, 0xa, 0x10)
+	DeviceWrite16(file, 0xc, 0x2000)
+	if n := file.Peek16(0x2); string(m.Memory[0x2000:0x2000+n]) != "0012 a.txt\n" {
+		t.Fatalf("Read %q", m.Memory[0x2000:0x2000+n])
+	}
+
+	DeviceWrite16(file, 0xc, 0x2000)
+	if n := file.Peek16(0x2); string(m.Memory[0x2000:0x2000+n]) != "---- sub/\n" {
+		t.Fatalf("Read %q", m.Memory[0x2000:0x2000+n])
+	}
+}
+
+func TestFileStatDelete(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "a.txt"), make([]byte, 0x1ab), 0o644)
+	m, file := fileMachine(root, "a.txt")
+
+	DeviceWrite16(file, 0xa, 4)
+	DeviceWrite16(file, 0x4, 0x2000)
+	if string(m.Memory[0x2000:0x2004]) != "01ab" {
+		t.Fatalf("Stat was %q", m.Memory[0x2000:0x2004])
+	}
+
+	file.DeviceWrite8(0x6, 1)
+	if file.Peek16(0x2) != 1 {
+		t.Fatal("File was not deleted")
+	}
+
+	DeviceWrite16(file, 0x4, 0x2000)
+	if string(m.Memory[0x2000:0x2004]) != "!!!!" {
+		t.Fatalf("Stat of a missing file was %q", m.Memory[0x2000:0x2004])
+	}
+}
+
+func TestFileSandbox(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	os.Mkdir(root, 0o755)
+	m, file := fileMachine(root, "../../escaped.txt")
+
+	copy(m.Memory[0x2000:], "data")
+	DeviceWrite16(file, 0xa, 4)
+	DeviceWrite16(file, 0xe, 0x2000)
+
+	if _, err := os.Stat(filepath.Join(parent, "escaped.txt")); err == nil {
+		t.Fatal("File was written outside of the root directory")
+	}
+	if _, err := os.Stat(filepath.Join(root, "escaped.txt")); err != nil {
+		t.Fatal("File was not written inside of the root directory")
+	}
+}
+
+func TestFileSandboxSymlink(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	os.Mkdir(root, 0o755)
+	os.WriteFile(filepath.Join(parent, "secret.txt"), []byte("secret"), 0o644)
+	os.WriteFile(filepath.Join(root, "inside.txt"), []byte("inside"), 0o644)
+	if err := os.Symlink("..", filepath.Join(root, "link")); err != nil {
+		t.Skip("Symbolic links are not supported:", err)
+	}
+	os.Symlink("../created.txt", filepath.Join(root, "dangling"))
+	os.Symlink("inside.txt", filepath.Join(root, "alias"))
+
+	// Links are followed inside of the root
+	m, file := fileMachine(root, "alias")
+	DeviceWrite16(file, 0xa, 6)
+	DeviceWrite16(file, 0xc, 0x2000)
+	if string(m.Memory[0x2000:0x2006]) != "inside" {
+		t.Fatalf("Read %q through a link inside of the root", m.Memory[0x2000:0x2006])
+	}
+
+	for _, name := range []string{"link/secret.txt", "link"} {
+		m, file := fileMachine(root, name)
+		DeviceWrite16(file, 0xa, 6)
+		DeviceWrite16(file, 0xc, 0x2000)
+		if file.Peek16(0x2) != 0 {
+			t.Fatalf("Read %q through %v", m.Memory[0x2000:0x2006], name)
+		}
+		DeviceWrite16(file, 0x4, 0x2000)
+		if string(m.Memory[0x2000:0x2004]) != "!!!!" {
+			t.Fatalf("Stat through %v was %q", name, m.Memory[0x2000:0x2004])
+		}
+	}
+
+	for _, name := range []string{"link/pwned.txt", "dangling"} {
+		m, file := fileMachine(root, name)
+		copy(m.Memory[0x2000:], "data")
+		DeviceWrite16(file, 0xa, 4)
+		DeviceWrite16(file, 0xe, 0x2000)
+		if file.Peek16(0x2) != 0 {
+			t.Fatalf("Wrote through %v", name)
+		}
+	}
+	for _, name := range []string{"pwned.txt", "created.txt"} {
+		if _, err := os.Stat(filepath.Join(parent, name)); err == nil {
+			t.Fatalf("%v was written outside of the root directory", name)
+		}
+	}
+
+	_, file = fileMachine(root, "link/secret.txt")
+	file.DeviceWrite8(0x6, 1)
+	if _, err := os.Stat(filepath.Join(parent, "secret.txt")); err != nil {
+		t.Fatal("File was deleted outside of the root directory")
+	}
+}
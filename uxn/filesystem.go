@@ -0,0 +1,337 @@
+package uxn
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A WritableFS is a file system that the File device can write to and delete
+// from, as well as read. File systems that are only an `fs.FS` are read-only
+//
+// As in `fs.FS`, names are slash-separated paths that are relative to the root
+// of the file system
+type WritableFS interface {
+	fs.FS
+	// OpenWriter opens a file for writing, creating it if it does not exist.
+	// When `appending` is set, writes are added to the end of the file, and
+	// otherwise the file is emptied first
+	OpenWriter(name string, appending bool) (io.WriteCloser, error)
+	// Remove deletes a file or an empty directory
+	Remove(name string) error
+}
+
+// DirFS returns a file system for the files inside of the directory `root` on
+// the host. Symbolic links are followed, but only while they lead to somewhere
+// inside of `root`
+func DirFS(root string) WritableFS {
+	return &hostFS{root: root}
+}
+
+type hostFS struct {
+	root string
+}
+
+// hostPath turns a name into a path on the host, rejecting the same names that
+// `os.DirFS` does
+func (h *hostFS) hostPath(op, name string) (string, error) {
+	if !fs.ValidPath(name) || runtime.GOOS == "windows" && strings.ContainsAny(name, `\:`) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(h.root, filepath.FromSlash(name)), nil
+}
+
+// resolve turns a name into a path on the host with every symbolic link
+// followed, and rejects it if it ends up outside of the root. Files that do
+// not exist yet are resolved through the directory they would be created in
+func (h *hostFS) resolve(op, name string) (string, error) {
+	p, err := h.hostPath(op, name)
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(h.root)
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	resolved, err := filepath.EvalSymlinks(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		// A link that leads nowhere would be followed when creating the file
+		if _, lerr := os.Lstat(p); lerr == nil {
+			return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrPermission}
+		}
+		var dir string
+		dir, err = filepath.EvalSymlinks(filepath.Dir(p))
+		resolved = filepath.Join(dir, filepath.Base(p))
+	}
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return "", &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrPermission}
+	}
+	return resolved, nil
+}
+
+func (h *hostFS) Open(name string) (fs.File, error) {
+	p, err := h.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (h *hostFS) OpenWriter(name string, appending bool) (io.WriteCloser, error) {
+	p, err := h.resolve("open", name)
+	if err != nil {
+		return nil, err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appending {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	return os.OpenFile(p, flags, 0o644)
+}
+
+// Remove only follows links to the directory holding the file, so that a link
+// is removed instead of the file it leads to
+func (h *hostFS) Remove(name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+	dir, err := h.resolve("remove", path.Dir(name))
+	if err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(dir, path.Base(name)))
+}
+
+// A MemFS is a file system that only exists in memory, which is useful for
+// testing programs, or giving them files without touching the disk
+//
+// Directories are never stored, and only exist while there are files in them
+type MemFS struct {
+	files map[string]*memFile
+}
+
+type memFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemFS creates an empty file system in memory
+func NewMemFS() *MemFS {
+	return &MemFS{files: map[string]*memFile{}}
+}
+
+// WriteFile stores a file, replacing it if it already exists
+func (m *MemFS) WriteFile(name string, data []byte) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	m.files[name] = &memFile{data: append([]byte(nil), data...), modTime: time.Now()}
+	return nil
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if file, ok := m.files[name]; ok {
+		return &memReader{Reader: bytes.NewReader(file.data), info: memInfo{name: path.Base(name), size: int64(len(file.data)), modTime: file.modTime}}, nil
+	}
+	entries, ok := m.list(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memDir{info: memInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// list returns the entries of a directory, sorted by name, or false if there
+// are no files in it
+func (m *MemFS) list(dir string) ([]fs.DirEntry, bool) {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	for name, file := range m.files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := name[len(prefix):]
+		child, _, isDir := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+		info := memInfo{name: child, dir: isDir}
+		if !isDir {
+			info.size, info.modTime = int64(len(file.data)), file.modTime
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, len(entries) > 0 || dir == "."
+}
+
+func (m *MemFS) OpenWriter(name string, appending bool) (io.WriteCloser, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if _, isDir := m.list(name); isDir {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	}
+	file, ok := m.files[name]
+	if !ok {
+		file = &memFile{}
+		m.files[name] = file
+	}
+	if !appending {
+		file.data = nil
+	}
+	file.modTime = time.Now()
+	return memWriter{file}, nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	if _, ok := m.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+// memInfo describes a file or directory in a `MemFS`
+type memInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// memReader is a file in a `MemFS` that has been opened for reading
+type memReader struct {
+	*bytes.Reader
+	info memInfo
+}
+
+func (r *memReader) Stat() (fs.FileInfo, error) { return r.info, nil }
+func (r *memReader) Close() error               { return nil }
+
+// memDir is a directory in a `MemFS` that has been opened for listing
+type memDir struct {
+	info    memInfo
+	entries []fs.DirEntry
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// memWriter is a file in a `MemFS` that has been opened for writing
+type memWriter struct {
+	file *memFile
+}
+
+func (w memWriter) Write(p []byte) (int, error) {
+	w.file.data = append(w.file.data, p...)
+	return len(p), nil
+}
+
+func (w memWriter) Close() error { return nil }
+
+// readOnlyFS hides the writing methods of a file system
+type readOnlyFS struct {
+	fs.FS
+}
+
+// ZipFS returns a read-only file system for the files in a zip archive
+func ZipFS(r io.ReaderAt, size int64) (fs.FS, error) {
+	return zip.NewReader(r, size)
+}
+
+// TarFS reads every file in a tar archive into memory, and returns a read-only
+// file system for them
+func TarFS(r io.Reader) (fs.FS, error) {
+	files := NewMemFS()
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if err := files.WriteFile(name, data); err != nil {
+			return nil, err
+		}
+		files.files[name].modTime = header.ModTime
+	}
+	return readOnlyFS{files}, nil
+}
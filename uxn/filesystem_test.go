@@ -0,0 +1,97 @@
+package uxn
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io/fs"
+	"testing"
+)
+
+// Tests the file systems that the File device can use
+
+func TestMemFS(t *testing.T) {
+	files := NewMemFS()
+	files.WriteFile("notes/a.txt", []byte("hi"))
+	m, file := fsMachine(files, "notes/b.txt")
+
+	copy(m.Memory[0x2000:], "hello")
+	DeviceWrite16(file, 0xa, 5)
+	DeviceWrite16(file, 0xe, 0x2000)
+
+	data, err := fs.ReadFile(files, "notes/b.txt")
+	if err != nil || string(data) != "hello" {
+		t.Fatalf("File contained %q, %v", data, err)
+	}
+
+	// Directories exist while there are files in them
+	copy(m.Memory[0x1000:], "notes\x00")
+	DeviceWrite16(file, 0x8, 0x1000)
+	DeviceWrite16(file, 0xa, 0x100)
+	DeviceWrite16(file, 0xc, 0x2000)
+	expected := "0002 a.txt\n0005 b.txt\n"
+	if n := file.Peek16(0x2); string(m.Memory[0x2000:0x2000+n]) != expected {
+		t.Logf("Actual: %q", m.Memory[0x2000:0x2000+n])
+		t.Logf("Expect: %q", expected)
+		t.Fatal("Listings differed")
+	}
+
+	file.DeviceWrite8(0x6, 1)
+	if file.Peek16(0x2) != 0 {
+		t.Fatal("Deleted a directory that is not stored")
+	}
+}
+
+func TestZipFS(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, _ := archive.Create("assets/sprite.chr")
+	w.Write([]byte{0x18, 0x3c})
+	archive.Close()
+
+	files, err := ZipFS(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, file := fsMachine(files, "assets/sprite.chr")
+
+	DeviceWrite16(file, 0xa, 2)
+	DeviceWrite16(file, 0xc, 0x2000)
+	if file.Peek16(0x2) != 2 || m.Memory[0x2000] != 0x18 || m.Memory[0x2001] != 0x3c {
+		t.Fatalf("Read %v bytes: %v", file.Peek16(0x2), HexPrint(m.Memory[0x2000:0x2002]))
+	}
+
+	// Archives are read-only
+	DeviceWrite16(file, 0xe, 0x2000)
+	if file.Peek16(0x2) != 0 {
+		t.Fatal("Wrote to a zip archive")
+	}
+}
+
+func TestTarFS(t *testing.T) {
+	var buf bytes.Buffer
+	archive := tar.NewWriter(&buf)
+	archive.WriteHeader(&tar.Header{Name: "./level.dat", Mode: 0o644, Size: 3})
+	archive.Write([]byte("abc"))
+	archive.Close()
+
+	files, err := TarFS(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := files.(WritableFS); ok {
+		t.Fatal("Tar archive was writable")
+	}
+	m, file := fsMachine(files, "level.dat")
+
+	DeviceWrite16(file, 0xa, 4)
+	DeviceWrite16(file, 0x4, 0x2000)
+	if string(m.Memory[0x2000:0x2004]) != "0003" {
+		t.Fatalf("Stat was %q", m.Memory[0x2000:0x2004])
+	}
+
+	file.DeviceWrite8(0x6, 1)
+	if file.Peek16(0x2) != 0 {
+		t.Fatal("Deleted from a tar archive")
+	}
+}
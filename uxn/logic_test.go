@@ -0,0 +1,602 @@
+package uxn
+
+import (
+	"testing"
+)
+
+// Tests the logic functions of the virtual machine
+
+func TestEQU(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x12, 0x12})
+	m.Load([]byte{0x08}) // EQU
+	m.Step()
+
+	expected := CreateStack([]byte{0x01})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestEQUk(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
+	m.Load([]byte{0x88}) // EQUk
+	m.Step()
+
+	expected := CreateStack([]byte{0x12, 0x34, 0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestEQU2(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0xab, 0xcd, 0xef, 0x01})
+	m.Load([]byte{0x28}) // EQU2
+	m.Step()
+
+	expected := CreateStack([]byte{0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestEQU2k(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0xab, 0xcd, 0xab, 0xcd})
+	m.Load([]byte{0xa8}) // EQU2k
+	m.Step()
+
+	expected := CreateStack([]byte{0xab, 0xcd, 0xab, 0xcd, 0x01})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestNEQ(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x12, 0x12})
+	m.Load([]byte{0x09}) // NEQ
+	m.Step()
+
+	expected := CreateStack([]byte{0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestNEQk(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
+	m.Load([]byte{0x89}) // NEQk
+	m.Step()
+
+	expected := CreateStack([]byte{0x12, 0x34, 0x01})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestNEQ2(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0xab, 0xcd, 0xef, 0x01})
+	m.Load([]byte{0x29}) // NEQ2
+	m.Step()
+
+	expected := CreateStack([]byte{0x01})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestNEQ2k(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0xab, 0xcd, 0xab, 0xcd})
+	m.Load([]byte{0xa9}) // NEQ2k
+	m.Step()
+
+	expected := CreateStack([]byte{0xab, 0xcd, 0xab, 0xcd, 0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestGTH(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
+	m.Load([]byte{0x0a}) // GTH
+	m.Step()
+
+	expected := CreateStack([]byte{0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestGTHk(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x34, 0x12})
+	m.Load([]byte{0x8a}) // GTHk
+	m.Step()
+
+	expected := CreateStack([]byte{0x34, 0x12, 0x01})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestGTH2(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x34, 0x56, 0x12, 0x34})
+	m.Load([]byte{0x2a}) // GTH2
+	m.Step()
+
+	expected := CreateStack([]byte{0x01})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestGTH2k(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x34, 0x56})
+	m.Load([]byte{0xaa}) // GTH2k
+	m.Step()
+
+	expected := CreateStack([]byte{0x12, 0x34, 0x34, 0x56, 0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestLTH(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x01, 0x01})
+	m.Load([]byte{0x0b}) // LTH
+	m.Step()
+
+	expected := CreateStack([]byte{0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestLTHk(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x01, 0x00})
+	m.Load([]byte{0x8b}) // LTHk
+	m.Step()
+
+	expected := CreateStack([]byte{0x01, 0x00, 0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestLTH2(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x00, 0x01, 0x00, 0x00})
+	m.Load([]byte{0x2b}) // LTH2
+	m.Step()
+
+	expected := CreateStack([]byte{0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestLTH2k(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x00, 0x01, 0x00, 0x00})
+	m.Load([]byte{0xab}) // LTH2k
+	m.Step()
+
+	expected := CreateStack([]byte{0x00, 0x01, 0x00, 0x00, 0x00})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestJMP(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x02})
+	m.Load([]byte{0x0c}) // JMP
+	m.Step()
+
+	expected := CreateStack([]byte{})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x03 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x03)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJMPk(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x02})
+	m.Load([]byte{0x8c}) // JMPk
+	m.Step()
+
+	expected := CreateStack([]byte{0x02})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x03 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x03)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJMP2(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x00, 0x02})
+	m.Load([]byte{0x2c}) // JMP2
+	m.Step()
+
+	expected := CreateStack([]byte{})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != 0x02 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", 0x02)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJMP2k(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x00, 0x02})
+	m.Load([]byte{0xac}) // JMP2k
+	m.Step()
+
+	expected := CreateStack([]byte{0x00, 0x02})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != 0x02 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", 0x02)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJCN(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x00, 0x02})
+	m.Load([]byte{0x0d}) // JCN
+	m.Step()
+
+	expected := CreateStack([]byte{})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x01 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x01)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJCNk(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x01, 0x02})
+	m.Load([]byte{0x8d}) // JCNk
+	m.Step()
+
+	expected := CreateStack([]byte{0x01, 0x02})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x03 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x03)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJCN2(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x01, 0x00, 0x02})
+	m.Load([]byte{0x2d}) // JCN2
+	m.Step()
+
+	expected := CreateStack([]byte{})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != 0x02 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", 0x02)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJCN2k(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x00, 0x00, 0x02})
+	m.Load([]byte{0xad}) // JCN2k
+	m.Step()
+
+	expected := CreateStack([]byte{0x00, 0x00, 0x02})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x01 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x01)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJSR(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x02})
+	m.Load([]byte{0x0e}) // JSR
+	m.Step()
+
+	expected := CreateStack([]byte{})
+	expectedReturn := CreateStack([]byte{byte(ProgramStartPage >> 8), byte(ProgramStartPage&0xff) + 1})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ReturnStack.String() != expectedReturn.String() {
+		t.Logf("Actual: %v", m.ReturnStack.String())
+		t.Logf("Expect: %v", expectedReturn.String())
+		t.Fatal("Return stacks differed")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x03 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x03)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJSRk(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x02})
+	m.Load([]byte{0x8e}) // JSRk
+	m.Step()
+
+	expected := CreateStack([]byte{0x02})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x03 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x03)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJSR2(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x00, 0x02})
+	m.Load([]byte{0x2e}) // JSR2
+	m.Step()
+
+	expected := CreateStack([]byte{})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != 0x02 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", 0x02)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJSR2k(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x00, 0x02})
+	m.Load([]byte{0xae}) // JSR2k
+	m.Step()
+
+	expected := CreateStack([]byte{0x00, 0x02})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != 0x02 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", 0x02)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestBRK(t *testing.T) {
+	var m Machine
+	m.Load([]byte{0x80, 0x12, 0x00, 0x80, 0x34}) // LIT 12 BRK LIT 34
+	m.RunVector(ProgramStartPage)
+
+	expected := CreateStack([]byte{0x12})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.Halted {
+		t.Fatal("BRK halted the machine")
+	}
+}
+
+func TestJCI(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x01})
+	m.Load([]byte{0x20, 0x00, 0x10}) // JCI 0010
+	m.Step()
+
+	if m.WorkingStack.Pointer != 0 {
+		t.Logf("Actual Pointer: %v", m.WorkingStack.Pointer)
+		t.Fatal("JCI did not consume its condition")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x13 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x13)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJCINotTaken(t *testing.T) {
+	var m Machine
+	m.WorkingStack = CreateStack([]byte{0x00})
+	m.Load([]byte{0x20, 0x00, 0x10}) // JCI 0010
+	m.Step()
+
+	if m.ProgramCounter != ProgramStartPage+0x03 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x03)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJMI(t *testing.T) {
+	var m Machine
+	m.Load([]byte{0x40, 0xff, 0xfd}) // JMI fffd
+	m.Step()
+
+	if m.ProgramCounter != ProgramStartPage {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJSI(t *testing.T) {
+	var m Machine
+	m.Load([]byte{0x60, 0x00, 0x10}) // JSI 0010
+	m.Step()
+
+	expected := CreateStack([]byte{0x01, 0x03})
+
+	if m.ReturnStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.ReturnStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x13 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x13)
+		t.Fatal("Program counters differed")
+	}
+}
+
+func TestJCILegacy(t *testing.T) {
+	var m Machine
+	m.Revision = RevisionLegacy
+	m.Load([]byte{0x20, 0x00, 0x10}) // LIT2 00 10
+	m.Step()
+
+	expected := CreateStack([]byte{0x00, 0x10})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
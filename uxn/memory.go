@@ -0,0 +1,61 @@
+package uxn
+
+// The memory of a machine is split into banks of 64k each. The first bank is
+// `Machine.Memory`, which is the only one that the program can address
+// directly, and the rest can only be reached through the System device's
+// expansion port
+const (
+	BankSize  = 0x10000
+	BankCount = 0x10
+)
+
+// The commands understood by the expansion port
+// Reference: https://wiki.xxiivv.com/site/varvara.html#system
+const (
+	expansionFill      = 0x00
+	expansionCopyLeft  = 0x01
+	expansionCopyRight = 0x02
+)
+
+// Bank returns one of the banks of memory, where bank 0 is `Memory`. Numbers
+// past the last bank wrap around, and banks are allocated when first used
+func (u *Machine) Bank(bank uint16) *[BankSize]byte {
+	bank %= BankCount
+	if bank == 0 {
+		return &u.Memory
+	}
+	if u.banks[bank-1] == nil {
+		u.banks[bank-1] = new([BankSize]byte)
+	}
+	return u.banks[bank-1]
+}
+
+// expand runs the expansion command stored in memory at `addr`
+//
+// A fill command is laid out as: 00 length* bank* addr* value
+// A copy command is laid out as: 01 or 02, length* src-bank* src-addr* dst-bank* dst-addr*
+//
+// Copying left copies the first byte first, and copying right copies the last
+// byte first, so that overlapping ranges can be moved in either direction
+func (u *Machine) expand(addr uint16) {
+	length := u.Peek16(addr + 1)
+	switch u.Peek8(addr) {
+	case expansionFill:
+		bank, start, value := u.Bank(u.Peek16(addr+3)), u.Peek16(addr+5), u.Peek8(addr+7)
+		for i := uint16(0); i < length; i++ {
+			bank[start+i] = value
+		}
+	case expansionCopyLeft:
+		src, srcStart := u.Bank(u.Peek16(addr+3)), u.Peek16(addr+5)
+		dst, dstStart := u.Bank(u.Peek16(addr+7)), u.Peek16(addr+9)
+		for i := uint16(0); i < length; i++ {
+			dst[dstStart+i] = src[srcStart+i]
+		}
+	case expansionCopyRight:
+		src, srcStart := u.Bank(u.Peek16(addr+3)), u.Peek16(addr+5)
+		dst, dstStart := u.Bank(u.Peek16(addr+7)), u.Peek16(addr+9)
+		for i := length; i > 0; i-- {
+			dst[dstStart+i-1] = src[srcStart+i-1]
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package uxn
+
+import (
+	"errors"
+	"testing"
+)
+
+// Tests loading roms into the banks of memory
+
+func TestLoad(t *testing.T) {
+	var m Machine
+	if err := m.Load([]byte{0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Memory[ProgramStartPage] != 0x01 || m.Memory[ProgramStartPage+1] != 0x02 {
+		t.Fatalf("Unexpected memory %v", HexPrint(m.Memory[ProgramStartPage:ProgramStartPage+2]))
+	}
+	if m.ProgramCounter != ProgramStartPage {
+		t.Fatalf("Program counter was %v", m.ProgramCounter)
+	}
+}
+
+func TestLoadSpillsIntoBanks(t *testing.T) {
+	var m Machine
+	rom := make([]byte, BankSize-int(ProgramStartPage)+2)
+	rom[len(rom)-3] = 0xaa
+	rom[len(rom)-2] = 0xbb
+	rom[len(rom)-1] = 0xcc
+
+	if err := m.Load(rom); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Memory[0xffff] != 0xaa {
+		t.Fatalf("Last byte of the first bank was %v", m.Memory[0xffff])
+	}
+	if m.Memory[0x0000] != 0x00 {
+		t.Fatal("Rom wrapped around into the zero page")
+	}
+	if bank := m.Bank(1); bank[0] != 0xbb || bank[1] != 0xcc {
+		t.Fatalf("Unexpected bank contents %v", HexPrint(bank[:2]))
+	}
+}
+
+func TestLoadTooLarge(t *testing.T) {
+	var m Machine
+	err := m.Load(make([]byte, MaxRomSize+1))
+
+	if !errors.Is(err, ErrRomTooLarge) {
+		t.Fatalf("Expected ErrRomTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package uxn
+
+// The buttons of the Mouse device, as the bits of its state port (0x6)
+const (
+	MouseLeft   byte = 0x01
+	MouseMiddle byte = 0x02
+	MouseRight  byte = 0x04
+)
+
+// A MouseInput is the state of the mouse, reported by a frontend
+type MouseInput struct {
+	// The position of the pointer, in pixels of the screen
+	X, Y int
+	// The buttons held down
+	Buttons byte
+	// How far the wheel was scrolled since the last input, where positive
+	// values scroll right and down
+	ScrollX, ScrollY int
+}
+
+// The MouseDevice gives the program the position of the pointer over the
+// screen, and the buttons held down
+// Reference: https://wiki.xxiivv.com/site/varvara.html#mouse
+type MouseDevice struct {
+	Ports
+}
+
+// NewMouseDevice creates a Mouse device
+func NewMouseDevice() *MouseDevice {
+	return &MouseDevice{}
+}
+
+// Send updates the ports from the input, and calls the Mouse vector if the
+// pointer moved, a button changed or the wheel was scrolled. The scroll ports
+// are only set while the vector runs
+//
+// It has to be called on the same goroutine as the machine, such as from a
+// frontend's loop
+func (m *MouseDevice) Send(in MouseInput) error {
+	x, y := uint16(in.X), uint16(in.Y)
+	if x == m.Peek16(0x2) && y == m.Peek16(0x4) && in.Buttons == m.Data[0x6] && in.ScrollX == 0 && in.ScrollY == 0 {
+		return nil
+	}
+
+	m.Poke16(0x2, x)
+	m.Poke16(0x4, y)
+	m.Data[0x6] = in.Buttons
+	m.Poke16(0xa, uint16(in.ScrollX))
+	m.Poke16(0xc, uint16(in.ScrollY))
+	defer func() {
+		m.Poke16(0xa, 0)
+		m.Poke16(0xc, 0)
+	}()
+	slot, ok := m.Slot()
+	if !ok {
+		return nil
+	}
+	return m.Machine.HandleEvent(Event{Device: slot})
+}
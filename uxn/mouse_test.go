@@ -0,0 +1,51 @@
+package uxn
+
+import (
+	"testing"
+)
+
+// Tests the pointer, buttons and wheel of the Mouse device
+
+// mouseEcho sets the Mouse vector to a handler that pushes the x position, the
+// state, and the vertical scroll every time it is called
+var mouseEcho = []byte{
+	0xa0, 0x01, 0x07, 0x80, 0x90, 0x37, 0x00, // LIT2 0107 LIT 90 DEO2 BRK
+	0x80, 0x93, 0x16, 0x80, 0x96, 0x16, 0x80, 0x9d, 0x16, 0x00, // LIT 93 DEI LIT 96 DEI LIT 9d DEI BRK
+}
+
+func TestMouseInput(t *testing.T) {
+	var m Machine
+	mouse := NewMouseDevice()
+	m.AddDevice(0x9, mouse)
+	m.Load(mouseEcho)
+	m.RunVector(ProgramStartPage)
+
+	inputs := []MouseInput{
+		{X: 0x10, Y: 0x20},
+		{X: 0x10, Y: 0x20}, // Nothing changed, so the vector is not called
+		{X: 0x10, Y: 0x20, Buttons: MouseLeft},
+		{X: 0x10, Y: 0x20, Buttons: MouseLeft, ScrollY: -1},
+		{X: 0x11, Y: 0x20},
+	}
+	for _, in := range inputs {
+		if err := mouse.Send(in); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := CreateStack([]byte{
+		0x10, 0x00, 0x00,
+		0x10, MouseLeft, 0x00,
+		0x10, MouseLeft, 0xff,
+		0x11, 0x00, 0x00,
+	})
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if mouse.Peek16(0xc) != 0 {
+		t.Fatal("Scroll was left in the scroll port")
+	}
+}
@@ -0,0 +1,27 @@
+package uxn
+
+import (
+	"image/color"
+)
+
+// A Palette holds the four colors that the screen is drawn with
+type Palette [4]color.RGBA
+
+// DecodePalette builds a palette from the System device's color ports, where
+// the red, green and blue channels are each stored as a short holding one
+// 4-bit value for every color
+//
+// Reference: https://wiki.xxiivv.com/site/varvara.html#system
+func DecodePalette(r, g, b uint16) Palette {
+	var p Palette
+	for i := range p {
+		shift := 12 - 4*i
+		p[i] = color.RGBA{
+			R: byte(r>>shift&0xf) * 0x11,
+			G: byte(g>>shift&0xf) * 0x11,
+			B: byte(b>>shift&0xf) * 0x11,
+			A: 0xff,
+		}
+	}
+	return p
+}
@@ -0,0 +1,81 @@
+package uxn
+
+import (
+	"image/color"
+	"testing"
+)
+
+// Tests turning the System colors into images of the screen
+
+func TestDecodePalette(t *testing.T) {
+	palette := DecodePalette(0x0f7f, 0x0fd6, 0x0f62)
+
+	expected := Palette{
+		{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+		{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		{R: 0x77, G: 0xdd, B: 0x66, A: 0xff},
+		{R: 0xff, G: 0x66, B: 0x22, A: 0xff},
+	}
+
+	if palette != expected {
+		t.Logf("Actual: %v", palette)
+		t.Logf("Expect: %v", expected)
+		t.Fatal("Palettes differed")
+	}
+}
+
+func TestSystemPalette(t *testing.T) {
+	var m Machine
+	system := NewSystemDevice(nil)
+	m.AddDevice(0x0, system)
+	DeviceWrite16(system, 0x8, 0xf000)
+	DeviceWrite16(system, 0xa, 0x0f00)
+	DeviceWrite16(system, 0xc, 0x00f0)
+
+	palette := *system.Palette()
+
+	if palette[0] != (color.RGBA{R: 0xff, A: 0xff}) || palette[1] != (color.RGBA{G: 0xff, A: 0xff}) || palette[2] != (color.RGBA{B: 0xff, A: 0xff}) {
+		t.Fatalf("Unexpected palette %v", palette)
+	}
+}
+
+func TestSystemPaletteLive(t *testing.T) {
+	var m Machine
+	system := NewSystemDevice(nil)
+	m.AddDevice(0x0, system)
+	palette := system.Palette()
+
+	// Only the first color has any red
+	system.DeviceWrite8(0x8, 0xf0)
+
+	if palette[0].R != 0xff || palette[1].R != 0x00 {
+		t.Fatalf("Palette was not updated after writing the red channel: %v", *palette)
+	}
+
+	system.Reset()
+
+	if palette[0].R != 0x00 {
+		t.Fatalf("Palette was not cleared after a reset: %v", *palette)
+	}
+}
+
+func TestFramebufferImage(t *testing.T) {
+	var f Framebuffer
+	f.Resize(2, 1)
+	f.Background[0] = 1
+	f.Background[1] = 1
+	f.Foreground[1] = 2
+	palette := DecodePalette(0x0f00, 0x00f0, 0x000f)
+
+	img := f.Image(palette)
+
+	if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 1 {
+		t.Fatalf("Unexpected image size %v", img.Bounds())
+	}
+	if img.At(0, 0) != palette[1] {
+		t.Fatalf("Background pixel was %v, expected %v", img.At(0, 0), palette[1])
+	}
+	if img.At(1, 0) != palette[2] {
+		t.Fatalf("Foreground pixel was %v, expected %v", img.At(1, 0), palette[2])
+	}
+}
@@ -0,0 +1,276 @@
+package uxn
+
+import (
+	"image"
+	"image/color"
+)
+
+// The size of the screen before the program resizes it
+const (
+	DefaultScreenWidth  = 64 * 8
+	DefaultScreenHeight = 40 * 8
+)
+
+// The screen can not be resized past these sizes, which are the same as in the
+// reference emulator
+const (
+	minScreenSize = 0x8
+	maxScreenSize = 0x400
+)
+
+// A Framebuffer holds the pixels drawn by the Screen device, which frontends
+// read to display the screen
+//
+// Each pixel is one of the four colors of the System palette (0-3), stored
+// row by row. Pixels in the foreground layer with color 0 are transparent, and
+// show the background layer underneath them
+//
+// The framebuffer is changed by the machine while a vector runs, so frontends
+// should only read it between vectors
+type Framebuffer struct {
+	Width, Height          int
+	Background, Foreground []byte
+	// Set whenever a pixel is drawn, and cleared by the frontend once it has
+	// displayed the changes
+	Changed bool
+}
+
+// Resize replaces the framebuffer with an empty one of the given size
+func (f *Framebuffer) Resize(width, height int) {
+	f.Width = width
+	f.Height = height
+	f.Background = make([]byte, width*height)
+	f.Foreground = make([]byte, width*height)
+	f.Changed = true
+}
+
+// At returns the color shown at the given pixel, with the foreground layer
+// drawn on top of the background
+func (f *Framebuffer) At(x, y int) byte {
+	i := x + y*f.Width
+	if color := f.Foreground[i]; color != 0 {
+		return color
+	}
+	return f.Background[i]
+}
+
+// Image composites both layers into an image, colored with `palette`
+func (f *Framebuffer) Image(palette Palette) *image.Paletted {
+	colors := make(color.Palette, len(palette))
+	for i := range palette {
+		colors[i] = palette[i]
+	}
+	img := image.NewPaletted(image.Rect(0, 0, f.Width, f.Height), colors)
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			img.Pix[y*img.Stride+x] = f.At(x, y)
+		}
+	}
+	return img
+}
+
+// fill sets every pixel of the layer from (x1, y1) up to (x2, y2) to `color`
+func (f *Framebuffer) fill(layer []byte, x1, y1, x2, y2 int, color byte) {
+	if x2 > f.Width {
+		x2 = f.Width
+	}
+	if y2 > f.Height {
+		y2 = f.Height
+	}
+	for y := y1; y < y2; y++ {
+		for x := x1; x < x2; x++ {
+			layer[x+y*f.Width] = color
+		}
+	}
+	f.Changed = true
+}
+
+// blending maps the color of each pixel in a sprite (the row) to the color
+// drawn on the screen for each of the 16 blending modes (the column)
+var blending = [4][16]byte{
+	{0, 0, 0, 0, 1, 0, 1, 1, 2, 2, 0, 2, 3, 3, 3, 0},
+	{0, 1, 2, 3, 0, 1, 2, 3, 0, 1, 2, 3, 0, 1, 2, 3},
+	{1, 2, 3, 1, 1, 2, 3, 1, 1, 2, 3, 1, 1, 2, 3, 1},
+	{2, 3, 1, 2, 2, 3, 1, 2, 2, 3, 1, 2, 2, 3, 1, 2},
+}
+
+// blit draws a single 8x8 sprite from memory, stored as either 1 bit per pixel
+// (8 bytes), or 2 bits per pixel (16 bytes)
+func (f *Framebuffer) blit(layer []byte, memory *[65536]byte, addr, x1, y1 uint16, blend byte, flipX, flipY, twoBpp bool) {
+	// Blending modes 0x5, 0xa and 0xf leave the pixels with color 0 untouched
+	opaque := blend%5 != 0 || blend == 0
+	for v := uint16(0); v < 8; v++ {
+		c := uint16(memory[addr+v])
+		if twoBpp {
+			c |= uint16(memory[addr+v+8]) << 8
+		}
+		y := y1 + v
+		if flipY {
+			y = y1 + 7 - v
+		}
+		for h := 7; h >= 0; h-- {
+			ch := byte(c&1) | byte(c>>7)&2
+			c >>= 1
+			if !opaque && ch == 0 {
+				continue
+			}
+			x := x1 + uint16(h)
+			if flipX {
+				x = x1 + 7 - uint16(h)
+			}
+			if int(x) < f.Width && int(y) < f.Height {
+				layer[int(x)+int(y)*f.Width] = blending[ch][blend]
+			}
+		}
+	}
+	f.Changed = true
+}
+
+// ScreenDevice controls access to the Uxn machine's screen
+// Reference: https://wiki.xxiivv.com/site/varvara.html#screen
+type ScreenDevice struct {
+	Ports
+	// What the program has drawn so far
+	Framebuffer Framebuffer
+}
+
+// NewScreenDevice creates a Screen device with the default size
+func NewScreenDevice() *ScreenDevice {
+	s := &ScreenDevice{}
+	s.Framebuffer.Resize(DefaultScreenWidth, DefaultScreenHeight)
+	return s
+}
+
+// Reset clears the screen and returns it to the default size
+func (s *ScreenDevice) Reset() {
+	s.Ports.Reset()
+	s.Framebuffer.Resize(DefaultScreenWidth, DefaultScreenHeight)
+}
+
+func (s *ScreenDevice) DeviceRead8(port byte) byte {
+	switch port {
+	case 0x2:
+		return byte(s.Framebuffer.Width >> 8)
+	case 0x3:
+		return byte(s.Framebuffer.Width)
+	case 0x4:
+		return byte(s.Framebuffer.Height >> 8)
+	case 0x5:
+		return byte(s.Framebuffer.Height)
+	}
+	return s.Data[port]
+}
+
+func (s *ScreenDevice) DeviceWrite8(port, data byte) {
+	s.Data[port] = data
+	switch port {
+	case 0x3: // Resizes the screen once the whole width is written
+		s.resize(int(s.Peek16(0x2)), s.Framebuffer.Height)
+	case 0x5: // Resizes the screen once the whole height is written
+		s.resize(s.Framebuffer.Width, int(s.Peek16(0x4)))
+	case 0xe: // Write a pixel to the screen
+		s.drawPixel(data)
+	case 0xf: // Write a sprite to the screen
+		s.drawSprite(data)
+	}
+}
+
+func (s *ScreenDevice) resize(width, height int) {
+	if width < minScreenSize || height < minScreenSize || width >= maxScreenSize || height >= maxScreenSize {
+		return
+	}
+	s.Framebuffer.Resize(width, height)
+}
+
+// layer returns the layer chosen by bit 6 of a pixel or sprite byte
+func (s *ScreenDevice) layer(ctrl byte) []byte {
+	if ctrl&0x40 != 0 {
+		return s.Framebuffer.Foreground
+	}
+	return s.Framebuffer.Background
+}
+
+// drawPixel handles a write to the pixel port, which either draws a single
+// pixel, or fills the screen from the current position to one of its corners
+func (s *ScreenDevice) drawPixel(ctrl byte) {
+	f := &s.Framebuffer
+	color := ctrl & 0x3
+	x := s.Peek16(0x8)
+	y := s.Peek16(0xa)
+	layer := s.layer(ctrl)
+
+	// Fill mode
+	if ctrl&0x80 != 0 {
+		x1, y1, x2, y2 := int(x), int(y), f.Width, f.Height
+		if ctrl&0x10 != 0 {
+			x1, x2 = 0, int(x)
+		}
+		if ctrl&0x20 != 0 {
+			y1, y2 = 0, int(y)
+		}
+		f.fill(layer, x1, y1, x2, y2, color)
+		return
+	}
+
+	// Pixel mode
+	if int(x) < f.Width && int(y) < f.Height {
+		layer[int(x)+int(y)*f.Width] = color
+		f.Changed = true
+	}
+	auto := s.Data[0x6]
+	if auto&0x1 != 0 {
+		s.Poke16(0x8, x+1)
+	}
+	if auto&0x2 != 0 {
+		s.Poke16(0xa, y+1)
+	}
+}
+
+// drawSprite handles a write to the sprite port, which draws one or more 8x8
+// sprites from memory, depending on the length in the auto port
+func (s *ScreenDevice) drawSprite(ctrl byte) {
+	auto := s.Data[0x6]
+	length := uint16(auto >> 4)
+	twoBpp := ctrl&0x80 != 0
+	layer := s.layer(ctrl)
+	blend := ctrl & 0xf
+	flipX := ctrl&0x10 != 0
+	flipY := ctrl&0x20 != 0
+
+	x := s.Peek16(0x8)
+	y := s.Peek16(0xa)
+	addr := s.Peek16(0xc)
+
+	// How far each sprite moves, where negative steps wrap around
+	dx := uint16(auto&0x1) << 3
+	dy := uint16(auto&0x2) << 2
+	fx, fy := uint16(1), uint16(1)
+	if flipX {
+		fx = 0xffff
+	}
+	if flipY {
+		fy = 0xffff
+	}
+	addrStep := uint16(auto&0x4) << 1
+	if twoBpp {
+		addrStep <<= 1
+	}
+
+	// Repeated sprites are laid out across the axis that is not being advanced
+	dyx := dy * fx
+	dxy := dx * fy
+	for i := uint16(0); i <= length; i++ {
+		s.Framebuffer.blit(layer, &s.Machine.Memory, addr, x+dyx*i, y+dxy*i, blend, flipX, flipY, twoBpp)
+		addr += addrStep
+	}
+
+	if auto&0x1 != 0 {
+		s.Poke16(0x8, x+dx*fx)
+	}
+	if auto&0x2 != 0 {
+		s.Poke16(0xa, y+dy*fy)
+	}
+	if auto&0x4 != 0 {
+		s.Poke16(0xc, addr)
+	}
+}
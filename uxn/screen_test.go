@@ -0,0 +1,176 @@
+package uxn
+
+import (
+	"testing"
+)
+
+// Tests drawing to the Screen device
+
+func newScreenMachine() (*Machine, *ScreenDevice) {
+	m := New()
+	screen := NewScreenDevice()
+	m.AddDevice(0x2, screen)
+	return m, screen
+}
+
+func TestScreenSize(t *testing.T) {
+	_, screen := newScreenMachine()
+
+	if DeviceRead16(screen, 0x2) != DefaultScreenWidth || DeviceRead16(screen, 0x4) != DefaultScreenHeight {
+		t.Fatal("Screen did not start at the default size")
+	}
+
+	DeviceWrite16(screen, 0x2, 0x0100)
+	DeviceWrite16(screen, 0x4, 0x0080)
+
+	if screen.Framebuffer.Width != 0x100 || screen.Framebuffer.Height != 0x80 {
+		t.Logf("Actual size: %vx%v", screen.Framebuffer.Width, screen.Framebuffer.Height)
+		t.Fatal("Screen was not resized")
+	}
+	if len(screen.Framebuffer.Background) != 0x100*0x80 {
+		t.Fatal("Layers were not resized")
+	}
+	if DeviceRead16(screen, 0x2) != 0x100 || DeviceRead16(screen, 0x4) != 0x80 {
+		t.Fatal("Size ports did not report the new size")
+	}
+}
+
+func TestScreenMaxSize(t *testing.T) {
+	_, screen := newScreenMachine()
+
+	// As in the reference emulator, the screen is smaller than 0x400 pixels
+	DeviceWrite16(screen, 0x2, 0x03ff)
+	DeviceWrite16(screen, 0x2, 0x0400)
+	if screen.Framebuffer.Width != 0x3ff {
+		t.Fatalf("Screen was resized to %v pixels wide", screen.Framebuffer.Width)
+	}
+}
+
+func TestScreenPixel(t *testing.T) {
+	_, screen := newScreenMachine()
+	DeviceWrite16(screen, 0x8, 0x0010)
+	DeviceWrite16(screen, 0xa, 0x0020)
+	screen.DeviceWrite8(0x6, 0x01) // Auto x
+	screen.DeviceWrite8(0xe, 0x02) // Background, color 2
+	screen.DeviceWrite8(0xe, 0x43) // Foreground, color 3
+
+	if screen.Framebuffer.Background[0x10+0x20*DefaultScreenWidth] != 2 {
+		t.Fatal("Background pixel was not drawn")
+	}
+	if screen.Framebuffer.Foreground[0x11+0x20*DefaultScreenWidth] != 3 {
+		t.Fatal("Foreground pixel was not drawn at the incremented position")
+	}
+	if DeviceRead16(screen, 0x8) != 0x12 || DeviceRead16(screen, 0xa) != 0x20 {
+		t.Fatal("Position was not incremented")
+	}
+	if screen.Framebuffer.At(0x11, 0x20) != 3 || screen.Framebuffer.At(0x10, 0x20) != 2 {
+		t.Fatal("Layers were not composited")
+	}
+}
+
+func TestScreenFill(t *testing.T) {
+	_, screen := newScreenMachine()
+	DeviceWrite16(screen, 0x2, 0x0010)
+	DeviceWrite16(screen, 0x4, 0x0010)
+	DeviceWrite16(screen, 0x8, 0x0004)
+	DeviceWrite16(screen, 0xa, 0x0008)
+	screen.DeviceWrite8(0xe, 0x91) // Fill towards the top right, color 1
+
+	f := &screen.Framebuffer
+	for y := 0; y < f.Height; y++ {
+		for x := 0; x < f.Width; x++ {
+			var expected byte
+			if x < 4 && y >= 8 {
+				expected = 1
+			}
+			if f.Background[x+y*f.Width] != expected {
+				t.Fatalf("Pixel at %v,%v was %v, expected %v", x, y, f.Background[x+y*f.Width], expected)
+			}
+		}
+	}
+}
+
+func TestScreenSprite1bpp(t *testing.T) {
+	m, screen := newScreenMachine()
+	copy(m.Memory[0x200:], []byte{0x80, 0x40, 0x20, 0x10, 0x08, 0x04, 0x02, 0x01})
+	DeviceWrite16(screen, 0xc, 0x0200)
+	screen.DeviceWrite8(0xf, 0x01) // Background, 1bpp, blend 1
+
+	f := &screen.Framebuffer
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			var expected byte
+			if x == y {
+				expected = 1
+			}
+			if f.Background[x+y*f.Width] != expected {
+				t.Fatalf("Pixel at %v,%v was %v, expected %v", x, y, f.Background[x+y*f.Width], expected)
+			}
+		}
+	}
+
+	// Flipped horizontally, the diagonal goes the other way
+	screen.DeviceWrite8(0xf, 0x52) // Foreground, flip x, blend 2
+	for y := 0; y < 8; y++ {
+		if f.Foreground[7-y+y*f.Width] != 2 {
+			t.Fatalf("Flipped pixel at %v,%v was not drawn", 7-y, y)
+		}
+	}
+}
+
+func TestScreenSprite2bpp(t *testing.T) {
+	m, screen := newScreenMachine()
+	// The first row has colors 0, 1, 2, 3 from left to right
+	m.Memory[0x200] = 0x50
+	m.Memory[0x208] = 0x30
+	DeviceWrite16(screen, 0xc, 0x0200)
+	screen.DeviceWrite8(0xf, 0x81) // Background, 2bpp, blend 1
+
+	f := &screen.Framebuffer
+	for x, expected := range []byte{0, 1, 2, 3} {
+		if f.Background[x] != expected {
+			t.Fatalf("Pixel at %v,0 was %v, expected %v", x, f.Background[x], expected)
+		}
+	}
+}
+
+func TestScreenSpriteAuto(t *testing.T) {
+	m, screen := newScreenMachine()
+	for i := range m.Memory[0x200:0x210] {
+		m.Memory[0x200+i] = 0xff
+	}
+	DeviceWrite16(screen, 0xc, 0x0200)
+	screen.DeviceWrite8(0x6, 0x15) // Two sprites, auto x and addr
+	screen.DeviceWrite8(0xf, 0x01)
+
+	f := &screen.Framebuffer
+	if f.Background[0] != 1 || f.Background[0+15*f.Width] != 1 {
+		t.Fatal("Sprites were not drawn down the screen")
+	}
+	if f.Background[8] != 0 {
+		t.Fatal("Sprites were drawn across the screen")
+	}
+	if DeviceRead16(screen, 0x8) != 0x08 {
+		t.Fatal("Position was not advanced")
+	}
+	if DeviceRead16(screen, 0xc) != 0x0210 {
+		t.Logf("Actual addr: 0x%.4x", DeviceRead16(screen, 0xc))
+		t.Fatal("Address was not advanced past both sprites")
+	}
+}
+
+func TestScreenTransparentBlend(t *testing.T) {
+	m, screen := newScreenMachine()
+	m.Memory[0x200] = 0x80
+	DeviceWrite16(screen, 0xc, 0x0200)
+	screen.Framebuffer.Foreground[1] = 3
+	screen.DeviceWrite8(0xf, 0x45) // Foreground, blend 5
+
+	f := &screen.Framebuffer
+	if f.Foreground[0] != 1 {
+		t.Fatalf("Set pixel was %v, expected 1", f.Foreground[0])
+	}
+	if f.Foreground[1] != 3 {
+		t.Fatal("Transparent pixel was drawn over")
+	}
+}
@@ -1,4 +1,10 @@
-package main
+package uxn
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
 
 type UxnError byte
 
@@ -12,6 +18,8 @@ func (ue UxnError) Error() string {
 		return "Error: Overflow"
 	case ErrDivByZero:
 		return "Error: Divide by zero"
+	case ErrNoDevice:
+		return "Error: No such device"
 	}
 	panic("Unknown error")
 }
@@ -21,10 +29,11 @@ const (
 	ErrUnderflow
 	ErrOverflow
 	ErrDivByZero
+	ErrNoDevice
 )
 
 type Stack struct {
-	Data    [254]byte
+	Data    [255]byte
 	Error   UxnError
 	Pointer byte
 }
@@ -33,10 +42,20 @@ func (s Stack) String() string {
 	return HexPrint(s.Data[:s.Pointer])
 }
 
+// Inspect prints the contents of the stack on a single line, labelled with
+// `name`, in the same format as uxncli
+func (s Stack) Inspect(w io.Writer, name string) {
+	contents := "empty"
+	if s.Pointer > 0 {
+		contents = strings.Trim(s.String(), "[]")
+	}
+	fmt.Fprintf(w, "<%s> %s\n", name, contents)
+}
+
 func (s *Stack) Push8(x byte) {
 	if s.Pointer == 0xff {
 		s.Error = ErrOverflow
-		panic("error")
+		panic(s.Error)
 	}
 	s.Data[s.Pointer] = x
 	s.Pointer++
@@ -60,7 +79,7 @@ func (s *Stack) Pop8(srcStackPtr *byte) byte {
 		panic(s.Error)
 	}
 	*srcStackPtr--
-	return s.Data[s.Pointer]
+	return s.Data[*srcStackPtr]
 }
 
 func (s *Stack) Pop16(srcStackPtr *byte) uint16 {
@@ -1,4 +1,4 @@
-package main
+package uxn
 
 import (
 	"testing"
@@ -18,13 +18,13 @@ func CreateStack(bytes []byte) Stack {
 func TestLIT(t *testing.T) {
 	var m Machine
 	m.Load([]byte{0x80, 0x12}) // LIT 12
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -32,28 +32,92 @@ func TestLIT(t *testing.T) {
 func TestLIT2(t *testing.T) {
 	var m Machine
 	m.Load([]byte{0xa0, 0xab, 0xcd}) // LIT2 ab cd
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0xab, 0xcd})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
 
+func TestLITr(t *testing.T) {
+	var m Machine
+	m.Load([]byte{0xc0, 0x12}) // LITr 12
+	m.Step()
+
+	expected := CreateStack([]byte{0x12})
+
+	if m.ReturnStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.ReturnStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestLIT2r(t *testing.T) {
+	var m Machine
+	m.Load([]byte{0xe0, 0xab, 0xcd}) // LIT2r ab cd
+	m.Step()
+
+	expected := CreateStack([]byte{0xab, 0xcd})
+
+	if m.ReturnStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.ReturnStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestLITLegacy(t *testing.T) {
+	var m Machine
+	m.Revision = RevisionLegacy
+	m.Load([]byte{0x00, 0x12}) // LIT 12
+	m.Step()
+
+	expected := CreateStack([]byte{0x12})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+}
+
+func TestLIT2Legacy(t *testing.T) {
+	var m Machine
+	m.Revision = RevisionLegacy
+	m.Load([]byte{0x20, 0xab, 0xcd}) // LIT2 ab cd
+	m.Step()
+
+	expected := CreateStack([]byte{0xab, 0xcd})
+
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
+		t.Fatal("Stacks differed")
+	}
+
+	if m.ProgramCounter != ProgramStartPage+0x03 {
+		t.Logf("Actual ProgramCounter: 0x%.4x", m.ProgramCounter)
+		t.Logf("Expect ProgramCounter: 0x%.4x", ProgramStartPage+0x03)
+		t.Fatal("Program counters differed")
+	}
+}
+
 func TestINC(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x01})
 	m.Load([]byte{0x01}) // INC
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x02})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -62,13 +126,13 @@ func TestINCk(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x01})
 	m.Load([]byte{0x81}) // INCk
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x01, 0x02})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -77,13 +141,13 @@ func TestINC2(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x00, 0x01})
 	m.Load([]byte{0x21}) // INC2
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x00, 0x02})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -92,13 +156,13 @@ func TestINC2k(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x00, 0x01})
 	m.Load([]byte{0xa1}) // INC2k
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x00, 0x01, 0x00, 0x02})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -107,13 +171,13 @@ func TestPOP(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x02}) // POP
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -122,13 +186,13 @@ func TestPOPk(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x82}) // POPk
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -137,13 +201,13 @@ func TestPOP2(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x22}) // POP2
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -152,13 +216,13 @@ func TestPOP2k(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x82}) // POP2k
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -167,13 +231,13 @@ func TestNIP(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x03}) // NIP
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -182,13 +246,13 @@ func TestNIPk(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x83}) // NIPk
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -197,13 +261,13 @@ func TestNIP2(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56, 0x78})
 	m.Load([]byte{0x23}) // NIP2
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x56, 0x78})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -212,13 +276,13 @@ func TestNIP2k(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56, 0x78})
 	m.Load([]byte{0xa3}) // NIP2k
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x56, 0x78, 0x56, 0x78})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -227,13 +291,13 @@ func TestSWP(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x04}) // SWP
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x34, 0x12})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -242,13 +306,13 @@ func TestSWPk(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x84}) // SWPk
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x34, 0x12})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -257,13 +321,13 @@ func TestSWP2(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56, 0x78})
 	m.Load([]byte{0x24}) // SWP2
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x56, 0x78, 0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -272,13 +336,13 @@ func TestSWP2k(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56, 0x78})
 	m.Load([]byte{0xa4}) // SWP2k
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x56, 0x78, 0x56, 0x78, 0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 	}
 }
 
@@ -286,13 +350,13 @@ func TestROT(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56})
 	m.Load([]byte{0x05}) // ROT
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x34, 0x56, 0x12})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -301,13 +365,13 @@ func TestROTk(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56})
 	m.Load([]byte{0x85}) // ROTk
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x56, 0x34, 0x56, 0x12})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -316,13 +380,13 @@ func TestROT2(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc})
 	m.Load([]byte{0x25}) // ROT2
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x56, 0x78, 0x9a, 0xbc, 0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -331,13 +395,13 @@ func TestROT2k(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc})
 	m.Load([]byte{0xa5}) // ROT2k
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0x56, 0x78, 0x9a, 0xbc, 0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -346,13 +410,13 @@ func TestDUP(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x06}) // DUP
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -361,13 +425,13 @@ func TestDUPk(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x86}) // DUPk
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x34, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -376,13 +440,13 @@ func TestDUP2(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x26}) // DUP2
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -391,13 +455,13 @@ func TestDUP2k(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0xa6}) // DUP2k
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x12, 0x34, 0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -406,13 +470,13 @@ func TestOVR(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x07}) // OVR
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x12})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -421,13 +485,13 @@ func TestOVRk(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34})
 	m.Load([]byte{0x87}) // OVRk
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x12, 0x34, 0x12})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -436,13 +500,13 @@ func TestOVR2(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56, 0x78})
 	m.Load([]byte{0x27}) // OVR2
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x56, 0x78, 0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
@@ -451,13 +515,13 @@ func TestOVR2k(t *testing.T) {
 	var m Machine
 	m.WorkingStack = CreateStack([]byte{0x12, 0x34, 0x56, 0x78})
 	m.Load([]byte{0xa7}) // OVR2k
-	m.Execute()
+	m.Step()
 
 	expected := CreateStack([]byte{0x12, 0x34, 0x56, 0x78, 0x12, 0x34, 0x56, 0x78, 0x12, 0x34})
 
-	if m.WorkingStack.Data != expected.Data {
-		t.Logf("Actual: %v", m.WorkingStack.Data)
-		t.Logf("Expect: %v", expected.Data)
+	if m.WorkingStack.String() != expected.String() {
+		t.Logf("Actual: %v", m.WorkingStack.String())
+		t.Logf("Expect: %v", expected.String())
 		t.Fatal("Stacks differed")
 	}
 }
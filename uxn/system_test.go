@@ -0,0 +1,228 @@
+package uxn
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Tests the behavior of the System device
+
+func TestSystemHalt(t *testing.T) {
+	var m Machine
+	m.AddDevice(0x0, NewSystemDevice(nil))
+	m.Load([]byte{0x80, 0x85, 0x80, 0x0f, 0x17, 0x80, 0x12}) // LIT 85 LIT 0f DEO LIT 12
+	m.RunVector(ProgramStartPage)
+
+	if !m.Halted {
+		t.Fatal("Writing the state port did not halt the machine")
+	}
+
+	if m.ExitCode() != 0x05 {
+		t.Logf("Actual ExitCode: %v", m.ExitCode())
+		t.Logf("Expect ExitCode: %v", 0x05)
+		t.Fatal("Exit codes differed")
+	}
+
+	if m.WorkingStack.Pointer != 0 {
+		t.Fatal("Execution continued after halting")
+	}
+}
+
+func TestSystemZeroStateDoesNotHalt(t *testing.T) {
+	var m Machine
+	m.AddDevice(0x0, NewSystemDevice(nil))
+	m.Load([]byte{0x80, 0x00, 0x80, 0x0f, 0x17}) // LIT 00 LIT 0f DEO
+	m.Step()
+	m.Step()
+	m.Step()
+
+	if m.Halted {
+		t.Fatal("Writing a zero state halted the machine")
+	}
+}
+
+func TestSystemInspect(t *testing.T) {
+	var m Machine
+	var out strings.Builder
+	m.AddDevice(0x0, NewSystemDevice(&out))
+	m.ReturnStack = CreateStack([]byte{0x01, 0x23})
+	m.Load([]byte{0x80, 0x12, 0x80, 0x01, 0x80, 0x0e, 0x17}) // LIT 12 LIT 01 LIT 0e DEO
+	m.Step()
+	m.Step()
+	m.Step()
+	m.Step()
+
+	expected := "<wst> 12\n<rst> 01 23\n"
+
+	if out.String() != expected {
+		t.Logf("Actual: %q", out.String())
+		t.Logf("Expect: %q", expected)
+		t.Fatal("Inspected stacks differed")
+	}
+}
+
+func TestSystemInspectOrder(t *testing.T) {
+	var m Machine
+	var out strings.Builder
+	m.AddDevice(0x0, NewSystemDevice(&out))
+	m.AddDevice(0x1, NewConsoleDevice(nil, nil, &out))
+	m.Load([]byte{
+		0x80, 0x41, 0x80, 0x19, 0x17, // LIT 41 LIT 19 DEO
+		0xa0, 0x01, 0x0e, 0x17, // LIT2 010e DEO
+		0x80, 0x42, 0x80, 0x19, 0x17, // LIT 42 LIT 19 DEO
+	})
+	m.RunVector(ProgramStartPage)
+
+	// The stacks are printed in between the output around them
+	expected := "A<wst> empty\n<rst> empty\nB"
+	if out.String() != expected {
+		t.Logf("Actual: %q", out.String())
+		t.Logf("Expect: %q", expected)
+		t.Fatal("Output was out of order")
+	}
+}
+
+func TestSystemInspectEmpty(t *testing.T) {
+	var m Machine
+	var out strings.Builder
+	m.AddDevice(0x0, NewSystemDevice(&out))
+	m.Devices[0x0].DeviceWrite8(0x0e, 0x01)
+
+	expected := "<wst> empty\n<rst> empty\n"
+
+	if out.String() != expected {
+		t.Logf("Actual: %q", out.String())
+		t.Logf("Expect: %q", expected)
+		t.Fatal("Inspected stacks differed")
+	}
+}
+
+func TestIndependentMachines(t *testing.T) {
+	var wg sync.WaitGroup
+	machines := make([]*Machine, 8)
+	for i := range machines {
+		machines[i] = New()
+		machines[i].AddDevice(0x0, NewSystemDevice(nil))
+		machines[i].AddDevice(0x1, NewConsoleDevice(strings.NewReader(""), io.Discard, io.Discard))
+		machines[i].Load([]byte{0x80, byte(i + 1), 0x80, 0x0f, 0x17}) // LIT i+1 LIT 0f DEO
+		machines[i].CloseEvents()
+
+		wg.Add(1)
+		go func(m *Machine) {
+			defer wg.Done()
+			m.Run()
+		}(machines[i])
+	}
+	wg.Wait()
+
+	for i, m := range machines {
+		if m.ExitCode() != i+1 {
+			t.Logf("Actual ExitCode: %v", m.ExitCode())
+			t.Logf("Expect ExitCode: %v", i+1)
+			t.Fatal("Machines shared state")
+		}
+	}
+}
+
+func TestSystemExpansionFill(t *testing.T) {
+	var m Machine
+	system := NewSystemDevice(nil)
+	m.AddDevice(0x0, system)
+	copy(m.Memory[0x200:], []byte{0x00, 0x00, 0x03, 0x00, 0x01, 0xff, 0xff, 0xab}) // fill 3 bytes of bank 1 from ffff with ab
+	DeviceWrite16(system, 0x2, 0x200)
+
+	bank := m.Bank(1)
+	if bank[0xffff] != 0xab || bank[0x0000] != 0xab || bank[0x0001] != 0xab || bank[0x0002] != 0x00 {
+		t.Fatalf("Unexpected bank contents %v %v", HexPrint(bank[0xfffe:]), HexPrint(bank[:4]))
+	}
+	if m.Memory[0x0000] != 0 {
+		t.Fatal("Fill wrote to the wrong bank")
+	}
+}
+
+func TestSystemExpansionCopy(t *testing.T) {
+	var m Machine
+	system := NewSystemDevice(nil)
+	m.AddDevice(0x0, system)
+	copy(m.Memory[0x300:], []byte{1, 2, 3, 4})
+	copy(m.Memory[0x200:], []byte{0x01, 0x00, 0x04, 0x00, 0x00, 0x03, 0x00, 0x00, 0x02, 0x10, 0x00}) // copy 4 bytes from 0:0300 to 2:1000
+	DeviceWrite16(system, 0x2, 0x200)
+
+	bank := m.Bank(2)
+	expected := []byte{1, 2, 3, 4}
+	if string(bank[0x1000:0x1004]) != string(expected) {
+		t.Logf("Actual: %v", HexPrint(bank[0x1000:0x1004]))
+		t.Logf("Expect: %v", HexPrint(expected))
+		t.Fatal("Banks differed")
+	}
+}
+
+func TestSystemExpansionCopyOverlapping(t *testing.T) {
+	var m Machine
+	system := NewSystemDevice(nil)
+	m.AddDevice(0x0, system)
+	copy(m.Memory[0x300:], []byte{1, 2, 3, 4})
+
+	// Copying right moves a range up without overwriting it first
+	copy(m.Memory[0x200:], []byte{0x02, 0x00, 0x04, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x03, 0x01})
+	DeviceWrite16(system, 0x2, 0x200)
+
+	expected := []byte{1, 1, 2, 3, 4}
+	if string(m.Memory[0x300:0x305]) != string(expected) {
+		t.Logf("Actual: %v", HexPrint(m.Memory[0x300:0x305]))
+		t.Logf("Expect: %v", HexPrint(expected))
+		t.Fatal("Memory differed")
+	}
+
+	// Copying left moves it back down
+	copy(m.Memory[0x200:], []byte{0x01, 0x00, 0x04, 0x00, 0x00, 0x03, 0x01, 0x00, 0x00, 0x03, 0x00})
+	DeviceWrite16(system, 0x2, 0x200)
+
+	expected = []byte{1, 2, 3, 4, 4}
+	if string(m.Memory[0x300:0x305]) != string(expected) {
+		t.Logf("Actual: %v", HexPrint(m.Memory[0x300:0x305]))
+		t.Logf("Expect: %v", HexPrint(expected))
+		t.Fatal("Memory differed")
+	}
+}
+
+func TestSystemStackPointers(t *testing.T) {
+	var m Machine
+	system := NewSystemDevice(nil)
+	m.AddDevice(0x0, system)
+	m.WorkingStack.Push8(0x12)
+
+	if system.DeviceRead8(0x4) != 1 {
+		t.Fatalf("Working stack pointer read as %v", system.DeviceRead8(0x4))
+	}
+
+	system.DeviceWrite8(0x4, 0)
+	system.DeviceWrite8(0x5, 3)
+
+	if m.WorkingStack.Pointer != 0 || m.ReturnStack.Pointer != 3 {
+		t.Fatalf("Unexpected stack pointers %v %v", m.WorkingStack.Pointer, m.ReturnStack.Pointer)
+	}
+}
+
+func TestSystemUnattached(t *testing.T) {
+	system := NewSystemDevice(nil)
+
+	// Without a machine, the ports only hold what was written to them
+	for _, port := range []byte{0x3, 0x4, 0x5, 0xe, 0xf} {
+		system.DeviceWrite8(port, 0x12)
+		if system.DeviceRead8(port) != 0x12 {
+			t.Fatalf("Port %#x read as %#x", port, system.DeviceRead8(port))
+		}
+	}
+
+	// Ports past the last one wrap around, as with `Ports`
+	system.DeviceWrite8(0x18, 0xf0)
+	if system.Data[0x8] != 0xf0 || system.DeviceRead8(0x28) != 0xf0 {
+		t.Fatal("Port was not masked")
+	}
+	if system.Palette()[0].R != 0xff {
+		t.Fatalf("Palette was not updated, %v", system.Palette()[0])
+	}
+}
@@ -1,8 +1,15 @@
-package main
+// Package uxn implements the Uxn virtual machine, along with the Varvara
+// devices that programs use to talk to the host
+//
+// Reference: https://wiki.xxiivv.com/site/uxn.html
+package uxn
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"strings"
+	"sync"
 )
 
 func HexPrint(arr []byte) string {
@@ -21,39 +28,78 @@ func HexPrint(arr []byte) string {
 // Page of memory where the program starts executing
 const ProgramStartPage uint16 = 0x100
 
-type Uxn struct {
+// A Revision selects which version of the Uxn opcode table is used to decode
+// instructions
+type Revision byte
+
+const (
+	// RevisionCurrent is the opcode table used by current assemblers, where
+	// 0x00 is BRK, 0x20, 0x40 and 0x60 are the immediate jumps JCI, JMI and JSI,
+	// and the literals are only reachable with the keep flag set (0x80 LIT)
+	RevisionCurrent Revision = iota
+	// RevisionLegacy is the original opcode table, where every instruction with
+	// the low 5 bits unset is a literal, so 0x00 is LIT and there is no BRK
+	RevisionLegacy
+)
+
+// A Machine is a single Uxn CPU, with its memory, stacks, and the devices
+// connected to it
+type Machine struct {
 	// The stacks that the machine uses
 	WorkingStack, ReturnStack Stack
 	// Swapped during return mode
 	Src, Dst *Stack
 	// A list of external devices that the machine can access
 	Devices [16]Device
-	// 64k of memory
-	Memory [65536]byte
+	// The first 64k bank of memory, which is the only one that instructions
+	// can address
+	Memory [BankSize]byte
+	// The rest of the banks, which are only reached through the System
+	// device's expansion port, and are allocated when first used
+	banks [BankCount - 1]*[BankSize]byte
 	// The current element in memory
 	ProgramCounter uint16
 	// Whether the program should continue executing
 	Halted bool
+	// The opcode table used to decode instructions
+	Revision Revision
+	// Set when a BRK instruction ends the vector that is currently running
+	broken bool
+	// Events posted by devices, waiting for their vectors to be called
+	events     chan Event
+	eventsOnce sync.Once
+	closeOnce  sync.Once
+	// Held while posting, so that the queue is never closed in the middle of
+	// a post. Closing `closing` wakes up posts that are waiting for room
+	closeLock sync.RWMutex
+	closed    bool
+	closing   chan struct{}
+}
+
+// New creates a machine with empty memory and no devices connected. Devices
+// are connected with `AddDevice`, and a program with `Load`
+func New() *Machine {
+	return &Machine{}
 }
 
-func (u *Uxn) Poke8(at uint16, data byte) {
+func (u *Machine) Poke8(at uint16, data byte) {
 	u.Memory[at] = data
 }
 
-func (u *Uxn) Poke16(at uint16, data uint16) {
+func (u *Machine) Poke16(at uint16, data uint16) {
 	u.Memory[at] = byte(data >> 8)
 	u.Memory[at+1] = byte(data)
 }
 
-func (u *Uxn) Peek16(at uint16) uint16 {
+func (u *Machine) Peek16(at uint16) uint16 {
 	return uint16(u.Memory[at])<<8 + uint16(u.Memory[at+1])
 }
 
-func (u *Uxn) Peek8(at uint16) byte {
+func (u *Machine) Peek8(at uint16) byte {
 	return u.Memory[at]
 }
 
-func (u *Uxn) Warp8(x byte) {
+func (u *Machine) Warp8(x byte) {
 	s := int8(x)
 	if s < 0 {
 		u.ProgramCounter -= uint16(-s)
@@ -62,15 +108,42 @@ func (u *Uxn) Warp8(x byte) {
 	}
 }
 
-func (u *Uxn) Warp16(x uint16) {
+func (u *Machine) Warp16(x uint16) {
 	u.ProgramCounter = x
 }
 
-// Execute takes a single byte from the where the Program Counter is pointing in
+// Step takes a single byte from the where the Program Counter is pointing in
 // memory and executes it
-func (u *Uxn) Execute() {
+//
+// If the instruction faults, the stack pointers are put back to where they
+// were before it ran, so that its operands are still on the stacks, and a
+// `*Fault` describing it is returned. The program counter is left past the
+// instruction
+func (u *Machine) Step() (err error) {
+	start := u.ProgramCounter
 	instr := u.Memory[u.ProgramCounter]
 	u.ProgramCounter++
+	working, ret := u.WorkingStack.Pointer, u.ReturnStack.Pointer
+
+	// The stacks and arithmetic signal faults by panicking with a `UxnError`,
+	// which is turned into a `Fault` here so that it never reaches the host
+	defer func() {
+		if r := recover(); r != nil {
+			kind, ok := r.(UxnError)
+			if !ok {
+				panic(r)
+			}
+			u.WorkingStack.Pointer, u.ReturnStack.Pointer = working, ret
+			err = &Fault{
+				Kind:           kind,
+				Instruction:    instr,
+				ProgramCounter: start,
+				WorkingStack:   u.WorkingStack,
+				ReturnStack:    u.ReturnStack,
+			}
+		}
+	}()
+
 	// Return Mode
 	if instr&0x40 != 0 {
 		u.Src = &u.ReturnStack
@@ -97,17 +170,34 @@ func (u *Uxn) Execute() {
 	// Get the top 5 bytes of the instruction
 	switch instr & 0x1f {
 	/* Stack */
-	case 0x00: /* LIT */
-		if shortMode {
-			a := u.Peek16(u.ProgramCounter)
-			u.Src.Push16(a)
-		} else {
-			a := u.Peek8(u.ProgramCounter)
-			u.Src.Push8(a)
-		}
-		u.ProgramCounter++
-		if shortMode {
+	case 0x00:
+		switch {
+		case u.Revision == RevisionLegacy || instr&0x80 != 0: // LIT
+			if shortMode {
+				a := u.Peek16(u.ProgramCounter)
+				u.Src.Push16(a)
+			} else {
+				a := u.Peek8(u.ProgramCounter)
+				u.Src.Push8(a)
+			}
 			u.ProgramCounter++
+			if shortMode {
+				u.ProgramCounter++
+			}
+		case instr == 0x00: // BRK
+			u.broken = true
+		case instr == 0x20: // JCI
+			a := u.Src.Pop8(srcStackPtr)
+			if a != 0x00 {
+				u.Warp16(u.ProgramCounter + 2 + u.Peek16(u.ProgramCounter))
+			} else {
+				u.ProgramCounter += 2
+			}
+		case instr == 0x40: // JMI
+			u.Warp16(u.ProgramCounter + 2 + u.Peek16(u.ProgramCounter))
+		case instr == 0x60: // JSI
+			u.ReturnStack.Push16(u.ProgramCounter + 2)
+			u.Warp16(u.ProgramCounter + 2 + u.Peek16(u.ProgramCounter))
 		}
 	case 0x01: /* INC */
 		if shortMode {
@@ -372,27 +462,29 @@ func (u *Uxn) Execute() {
 		}
 	case 0x16: // DEI
 		deviceIndex := u.Src.Pop8(srcStackPtr)
-		if u.Devices[deviceIndex>>4].u == nil {
-			panic(fmt.Sprintf("Device does not exist: %.2x", deviceIndex))
+		device := u.Devices[deviceIndex>>4]
+		if device == nil {
+			panic(ErrNoDevice)
 		}
 		if shortMode {
-			b := u.Devices[deviceIndex>>4].DeviceRead16(deviceIndex)
+			b := DeviceRead16(device, deviceIndex)
 			u.Src.Push16(b)
 		} else {
-			b := u.Devices[deviceIndex>>4].DeviceRead8(deviceIndex)
+			b := device.DeviceRead8(deviceIndex & 0x0f)
 			u.Src.Push8(b)
 		}
 	case 0x17: // DEO
 		deviceIndex := u.Src.Pop8(srcStackPtr)
-		if u.Devices[deviceIndex>>4].u == nil {
-			panic(fmt.Sprintf("Device does not exist: %.2x", deviceIndex))
+		device := u.Devices[deviceIndex>>4]
+		if device == nil {
+			panic(ErrNoDevice)
 		}
 		if shortMode {
 			b := u.Src.Pop16(srcStackPtr)
-			u.Devices[deviceIndex>>4].DeviceWrite16(deviceIndex, b)
+			DeviceWrite16(device, deviceIndex, b)
 		} else {
 			b := u.Src.Pop8(srcStackPtr)
-			u.Devices[deviceIndex>>4].DeviceWrite8(deviceIndex, b)
+			device.DeviceWrite8(deviceIndex&0x0f, b)
 		}
 	// Arithmetic
 	case 0x18: // ADD
@@ -485,18 +577,182 @@ func (u *Uxn) Execute() {
 	default:
 		panic(fmt.Sprintf("Unhandled instruction %.2x", instr&0x1f))
 	}
+	return nil
+}
+
+// RunVector executes instructions starting at `addr` until a BRK instruction
+// is reached or the machine halts, and then flushes the output of every device
+// that is a `Flusher`
+//
+// If an instruction faults, the program's System vector is called to handle it.
+// When there is no vector to call, or the vector faults itself, the machine
+// halts and the `*Fault` is returned
+func (u *Machine) RunVector(addr uint16) error {
+	u.ProgramCounter = addr
+	u.broken = false
+	catching := false
+	for !u.broken && !u.Halted {
+		err := u.Step()
+		if err == nil {
+			continue
+		}
+		var fault *Fault
+		if !catching && errors.As(err, &fault) && u.catch(fault) {
+			catching = true
+			continue
+		}
+		u.Halted = true
+		u.flush()
+		return err
+	}
+	return u.flush()
+}
+
+// flush writes out everything that the devices have buffered for the host,
+// returning the first error encountered
+func (u *Machine) flush() error {
+	var first error
+	for _, device := range u.Devices {
+		if flusher, ok := device.(Flusher); ok {
+			if err := flusher.Flush(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// Run executes the program from `ProgramStartPage`, then calls the vectors of
+// the devices as they post events, until the machine halts or the event queue
+// is closed
+//
+// The queue is closed with `CloseEvents`, or once every device that is an
+// `EventSource` has stopped listening
+func (u *Machine) Run() error {
+	if err := u.RunVector(ProgramStartPage); err != nil {
+		return err
+	}
+
+	done := u.StartEventSources()
+	go func() {
+		<-done
+		u.CloseEvents()
+	}()
+	// Sources that are still posting when the program halts are woken up by
+	// closing the queue, so that they can return
+	defer u.CloseEvents()
+
+	for !u.Halted {
+		event, ok := <-u.eventQueue()
+		if !ok {
+			break
+		}
+		if err := u.HandleEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartEventSources calls Listen on every device that is an `EventSource`,
+// each on its own goroutine, and returns a channel that is closed once they
+// have all returned
+//
+// `Run` does this after the reset vector, and closes the event queue once the
+// sources are done. Hosts that drive the machine from their own loop with
+// `DispatchEvents` should start the sources in the same way, from the machine's
+// goroutine
+func (u *Machine) StartEventSources() <-chan struct{} {
+	var sources sync.WaitGroup
+	for slot, device := range u.Devices {
+		if source, ok := device.(EventSource); ok {
+			// Read on this goroutine, as the program can change them while
+			// the sources run
+			slot, vector := byte(slot), DeviceVector(device)
+			sources.Add(1)
+			go func() {
+				defer sources.Done()
+				source.Listen(slot, vector, u.Post)
+			}()
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		sources.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// Inspect prints the contents of both stacks to `w`
+func (u *Machine) Inspect(w io.Writer) {
+	u.WorkingStack.Inspect(w, "wst")
+	u.ReturnStack.Inspect(w, "rst")
+}
+
+// ExitCode returns the exit status requested by the program, which is the low
+// 7 bits of the state written to the System device's port 0x0f when halting
+func (u *Machine) ExitCode() int {
+	if u.Devices[0x0] == nil {
+		return 0
+	}
+	return int(u.Devices[0x0].DeviceRead8(0xf) & 0x7f)
 }
 
 // AddDevice links a device to a `uxn` virtual machine at the given port
-func (u *Uxn) AddDevice(port byte, device Device) {
+func (u *Machine) AddDevice(port byte, device Device) {
 	u.Devices[port] = device
-	u.Devices[port].u = u
+	device.Attach(u)
+}
+
+// Reset clears the stacks and resets every device, so that the program in
+// memory can be run again from the start
+func (u *Machine) Reset() {
+	u.WorkingStack = Stack{}
+	u.ReturnStack = Stack{}
+	u.ProgramCounter = ProgramStartPage
+	u.Halted = false
+	for _, device := range u.Devices {
+		if device != nil {
+			device.Reset()
+		}
+	}
 }
 
+// Close closes every device connected to the machine, returning the first
+// error encountered
+func (u *Machine) Close() error {
+	var first error
+	for _, device := range u.Devices {
+		if device == nil {
+			continue
+		}
+		if err := device.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// MaxRomSize is the largest rom that fits in memory, starting at
+// `ProgramStartPage` and continuing through every expansion bank
+const MaxRomSize = BankSize - int(ProgramStartPage) + (BankCount-1)*BankSize
+
+// ErrRomTooLarge is returned by `Load` for roms larger than `MaxRomSize`
+var ErrRomTooLarge = errors.New("uxn: rom does not fit in memory")
+
 // Load takes in a `uxn` rom and loads it into memory to be executed
-func (u *Uxn) Load(rom []byte) {
-	for offset := 0; offset < len(rom); offset++ {
-		u.Memory[ProgramStartPage+uint16(offset)] = rom[offset]
+//
+// The first 0xff00 bytes are placed at `ProgramStartPage`, and the rest spill
+// over into the expansion banks, starting at the beginning of bank 1
+func (u *Machine) Load(rom []byte) error {
+	if len(rom) > MaxRomSize {
+		return fmt.Errorf("%w: %v bytes is larger than %v", ErrRomTooLarge, len(rom), MaxRomSize)
+	}
+	loaded := copy(u.Memory[ProgramStartPage:], rom)
+	for bank := uint16(1); loaded < len(rom); bank++ {
+		loaded += copy(u.Bank(bank)[:], rom[loaded:])
 	}
 	u.ProgramCounter = ProgramStartPage
+	return nil
 }